@@ -1,11 +1,18 @@
 package resource
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"path"
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
+
+	"code.gitea.io/sdk/gitea"
 )
 
 type CheckRequest struct {
@@ -27,53 +34,338 @@ func (r CheckResponse) Less(i, j int) bool {
 	return r[j].CommittedDate.After(r[i].CommittedDate)
 }
 
-func Check(request CheckRequest, manager Gitea) (CheckResponse, error) {
+func Check(ctx context.Context, request CheckRequest, manager Gitea) (CheckResponse, error) {
 	var response CheckResponse
 
 	// Get pull requests
-	prs, err := manager.ListPullRequests(request.Source.State)
+	states := request.Source.SelectedStates()
+	prs, err := manager.ListPullRequests(ctx, stateFilter(states), updatedSince(request))
 	if err != nil {
 		return nil, err
 	}
 
 	DisableCISkip := request.Source.DisableCISkip
 
+	trusted, err := trustedAuthors(ctx, request.Source, manager)
+	if err != nil {
+		return nil, err
+	}
+
+	var trigger *regexp.Regexp
+	if request.Source.CommentTrigger != "" {
+		trigger, err = regexp.Compile(request.Source.CommentTrigger)
+		if err != nil {
+			return nil, fmt.Errorf("invalid comment trigger: %s", err)
+		}
+	}
+	writers := make(map[string]bool)
+	bases := make(map[string]*gitea.Branch)
+
+	// The base branch of the previous version, if its PR is still listed.
+	var previousBase string
+	for _, pr := range prs {
+		if strconv.FormatInt(pr.Index, 10) == request.Version.PR {
+			previousBase = pr.Base.Ref
+		}
+	}
+
+	// The timeline is needed both to date and to trust some PRs, but only listed once.
+	timelines := make(map[int64][]*TimelineEvent)
+	timeline := func(index int64) ([]*TimelineEvent, error) {
+		if events, ok := timelines[index]; ok {
+			return events, nil
+		}
+		events, err := manager.ListPullRequestTimeline(ctx, index)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list timeline: %s", err)
+		}
+		timelines[index] = events
+		return events, nil
+	}
+
+	// New versions must be dated after the previous version, and are held back if they
+	// are dated after an unmergeable PR which may still be checked for conflicts.
+	previous := request.Version.CommittedDate
+	var unsettled time.Time
+
 Loop:
 	for _, pr := range prs {
 		if !DisableCISkip && (ContainsSkipCI(pr.Title) || ContainsSkipCI(pr.Tip.RepoCommit.Message)) {
 			continue
 		}
 
+		if !pr.MatchesState(states) {
+			continue
+		}
+
 		if request.Source.BaseBranch != "" && pr.Base.Name != request.Source.BaseBranch {
 			continue
 		}
 
-		if !pr.UpdatedDate().After(request.Version.CommittedDate) {
+		if len(request.Source.BaseBranches) > 0 {
+			match, err := MatchBranch(request.Source.BaseBranches, pr.Base.Name)
+			if err != nil {
+				return nil, fmt.Errorf("base branch match failed: %s", err)
+			}
+			if !match {
+				continue
+			}
+		}
+
+		if len(request.Source.HeadBranches) > 0 {
+			match, err := MatchBranch(request.Source.HeadBranches, pr.Head.Name)
+			if err != nil {
+				return nil, fmt.Errorf("head branch match failed: %s", err)
+			}
+			if !match {
+				continue
+			}
+		}
+
+		// Gitea also reports PRs as unmergeable while they are WIP, which is left to
+		// ignore_drafts, and while checking them for conflicts. It does not record when
+		// they become mergeable, so versions dated after PRs which may still be checked
+		// are held back until they have settled.
+		if request.Source.SkipUnmergeable && pr.State == gitea.StateOpen && !pr.Mergeable &&
+			!pr.IsDraft(request.Source.WorkInProgressPrefixes()) {
+			if pr.Updated != nil && time.Since(*pr.Updated) < MergeableSettleTime {
+				date := pr.UpdatedDate()
+				if date.Before(previous) {
+					date = previous
+				}
+				if unsettled.IsZero() || date.Before(unsettled) {
+					unsettled = date
+				}
+			}
+			continue
+		}
+
+		if request.Source.DisableForks && pr.IsFork() {
+			continue
+		}
+
+		if request.Source.ForksOnly && !pr.IsFork() {
+			continue
+		}
+
+		if len(request.Source.Authors) > 0 {
+			match, err := MatchAuthor(request.Source.Authors, pr.AuthorLogin())
+			if err != nil {
+				return nil, fmt.Errorf("author match failed: %s", err)
+			}
+			if !match {
+				continue
+			}
+		}
+
+		if len(request.Source.IgnoreAuthors) > 0 {
+			match, err := MatchAuthor(request.Source.IgnoreAuthors, pr.AuthorLogin())
+			if err != nil {
+				return nil, fmt.Errorf("ignore author match failed: %s", err)
+			}
+			if match {
+				continue
+			}
+		}
+
+		if len(request.Source.Milestones) > 0 && !containsString(request.Source.Milestones, pr.MilestoneTitle()) {
+			continue
+		}
+
+		if len(request.Source.Assignees) > 0 {
+			assigned := false
+			for _, assignee := range pr.AssigneeLogins() {
+				if containsString(request.Source.Assignees, assignee) {
+					assigned = true
+					break
+				}
+			}
+			if !assigned {
+				continue
+			}
+		}
+
+		trust := trustReason(request.Source, pr, trusted)
+		if trust == "" {
+			continue
+		}
+		// The label only vouches for the head it was applied to, so it has to be applied
+		// again after every push.
+		var labelled time.Time
+		if trust == "ok_to_test" {
+			events, err := timeline(pr.Index)
+			if err != nil {
+				return nil, err
+			}
+			var ok bool
+			if labelled, ok = LabelDate(events, request.Source.TrustLabel()); !ok {
+				continue
+			}
+		}
+		// Versions record why they are trusted, so that get does not have to resolve
+		// the trusted teams again.
+		if trust == "unrestricted" {
+			trust = ""
+		}
+
+		if request.Source.IgnoreDrafts && pr.IsDraft(request.Source.WorkInProgressPrefixes()) {
+			continue
+		}
+
+		// Commit timestamps can not be relied on to detect new heads, since a force push
+		// may keep the original dates. Open PRs which were updated since the previous
+		// version, but whose tip is older, are dated by the last push in their timeline
+		// instead. Other updates, such as comments, do not change the date.
+		updated := pr.UpdatedDate()
+		samePR := request.Version.PR == strconv.FormatInt(pr.Index, 10)
+		if pr.State == gitea.StateOpen {
+			headChanged := samePR && request.Version.Commit != pr.Head.Sha
+			if (!samePR || headChanged) && !previous.IsZero() && !updated.After(previous) &&
+				pr.Updated != nil && pr.Updated.After(previous) {
+				events, err := timeline(pr.Index)
+				if err != nil {
+					return nil, err
+				}
+				if pushed := PushDate(events); pushed.After(updated) {
+					updated = pushed
+				}
+				// Leaving WIP does not push a commit either.
+				if request.Source.IgnoreDrafts {
+					if ready := ReadyDate(events, request.Source.WorkInProgressPrefixes()); ready.After(updated) {
+						updated = ready
+					}
+				}
+			}
+			// The head of the previous version was replaced, so a version must be emitted
+			// even if neither timestamp moved past it.
+			if headChanged && !updated.After(previous) {
+				updated = previous.Add(time.Second)
+			}
+		}
+
+		// Neither does labelling it as ok to test.
+		if labelled.After(updated) {
+			updated = labelled
+		}
+
+		// Approving a PR does not add a commit, so approved PRs are dated by
+		// the time they reached the required number of approvals.
+		if request.Source.RequiredReviewApprovals > 0 {
+			reviews, err := manager.ListPullReviews(ctx, pr.Index)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list reviews: %s", err)
+			}
+			approved, ok := ApprovalDate(reviews, request.Source.RequiredReviewApprovals, request.Source.OfficialReviewsOnly)
+			if !ok {
+				continue
+			}
+			if approved.After(updated) {
+				updated = approved
+			}
+		}
+
+		// Likewise for statuses posted by other CI systems.
+		if len(request.Source.RequiredStatusContexts) > 0 {
+			status, err := manager.GetCombinedStatus(ctx, pr.Head.Sha)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get combined status: %s", err)
+			}
+			succeeded, ok := StatusDate(status.Statuses, request.Source.RequiredStatusContexts)
+			if !ok {
+				continue
+			}
+			if succeeded.After(updated) {
+				updated = succeeded
+			}
+		}
+
+		// Open PRs are rebuilt when their base advances, pinned to the new base.
+		var baseCommit string
+		if request.Source.RebuildOnBaseChange && pr.State == gitea.StateOpen {
+			base, ok := bases[pr.Base.Ref]
+			if !ok {
+				base, err = manager.GetBranch(ctx, pr.Base.Ref)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get base branch: %s", err)
+				}
+				bases[pr.Base.Ref] = base
+			}
+			baseCommit = base.Commit.ID
+			if base.Commit.Timestamp.After(updated) {
+				updated = base.Commit.Timestamp
+			}
+			// The base may also move to an older commit, e.g. when it is reset or an old
+			// branch is fast-forwarded into it, so the base of the previous version is
+			// compared as well, for all PRs on the same base.
+			sameBase := samePR || (previousBase != "" && pr.Base.Ref == previousBase && request.Version.BaseCommit != "")
+			if sameBase && request.Version.BaseCommit != baseCommit && !updated.After(previous) {
+				updated = previous.Add(time.Second)
+			}
+		}
+
+		// Comments matching the trigger rebuild the PR without a new commit.
+		var triggerComment *gitea.Comment
+		if trigger != nil {
+			comments, err := manager.ListPullRequestComments(ctx, pr.Index, request.Version.CommittedDate)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list comments: %s", err)
+			}
+			triggerComment, err = latestTriggerComment(ctx, comments, trigger, manager, writers)
+			if err != nil {
+				return nil, err
+			}
+			if triggerComment != nil && triggerComment.Created.After(updated) {
+				updated = triggerComment.Created
+			} else {
+				triggerComment = nil
+			}
+		}
+
+		if !updated.After(request.Version.CommittedDate) {
 			continue
 		}
 
 		if len(request.Source.Labels) > 0 {
-			labelFound := false
-		LabelLoop:
+			labelsFound := 0
 			for _, targetLabel := range request.Source.Labels {
+			LabelLoop:
 				for _, prLabel := range pr.Labels {
-					if prLabel.Name == targetLabel {
-						labelFound = true
+					match, err := MatchLabel(targetLabel, prLabel.Name)
+					if err != nil {
+						return nil, fmt.Errorf("label match failed: %s", err)
+					}
+					if match {
+						labelsFound++
 						break LabelLoop
 					}
 				}
 			}
 
-			if !labelFound {
+			if labelsFound == 0 {
+				continue Loop
+			}
+			if request.Source.LabelsMatch == "all" && labelsFound < len(request.Source.Labels) {
 				continue Loop
 			}
 		}
 
+		for _, ignoredLabel := range request.Source.IgnoreLabels {
+			for _, prLabel := range pr.Labels {
+				match, err := MatchLabel(ignoredLabel, prLabel.Name)
+				if err != nil {
+					return nil, fmt.Errorf("ignore label match failed: %s", err)
+				}
+				if match {
+					continue Loop
+				}
+			}
+		}
+
 		// Fetch files once if paths/ignore_paths are specified.
 		var files []string
 
 		if len(request.Source.Paths) > 0 || len(request.Source.IgnorePaths) > 0 {
-			files, err = manager.ListModifiedFiles(pr.Index)
+			files, err = manager.ListModifiedFiles(ctx, pr.Index)
 			fmt.Printf("%s", files)
 			if err != nil {
 				return nil, fmt.Errorf("failed to list modified files: %s", err)
@@ -109,10 +401,98 @@ Loop:
 			}
 		}
 
-		response = append(response, NewVersion(pr))
+		// Emit a version for each commit pushed since the last version, besides the tip.
+		if request.Source.EveryCommit {
+			commits, err := manager.ListPullRequestCommits(ctx, pr.Index)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list commits: %s", err)
+			}
+			// Commits may be pushed long after they were made, so they are not found by
+			// their dates. For the PR of the previous version, the new commits are those
+			// listed (newest first) before its commit, or all of them if it is no longer
+			// part of the PR. For other PRs, they are the commits pushed since according
+			// to the timeline, unless the PR was opened since.
+			position := func(sha string) int {
+				for i, commit := range commits {
+					if commit.CommitMeta != nil && commit.SHA == sha {
+						return i
+					}
+				}
+				return len(commits)
+			}
+			listed := len(commits)
+			var pushedSince map[string]bool
+			switch {
+			case samePR:
+				listed = position(request.Version.Commit)
+			case previous.IsZero() || (pr.Created != nil && pr.Created.After(previous)):
+			default:
+				events, err := timeline(pr.Index)
+				if err != nil {
+					return nil, err
+				}
+				var forcedFrom string
+				pushedSince, forcedFrom = PushedCommits(events, previous)
+				listed = 0
+				if forcedFrom != "" {
+					listed = position(forcedFrom)
+				}
+			}
+			var pushed []*gitea.Commit
+			for i, commit := range commits {
+				if commit.CommitMeta == nil || commit.SHA == pr.Head.Sha || commit.SHA == pr.Tip.SHA {
+					continue
+				}
+				if i < listed || pushedSince[commit.SHA] {
+					pushed = append(pushed, commit)
+				}
+			}
+			// Like the tip, commits are dated no earlier than they were pushed, and
+			// dates never decrease, so that they are ordered as in the PR.
+			var last time.Time
+			for i := len(pushed) - 1; i >= 0; i-- {
+				commit := pushed[i]
+				committed := commit.Created
+				if !committed.After(previous) || committed.After(updated) {
+					committed = updated
+				}
+				if committed.Before(last) {
+					committed = last
+				}
+				last = committed
+
+				version := NewVersion(pr)
+				version.Commit = commit.SHA
+				version.CommittedDate = committed.UTC()
+				version.BaseCommit = baseCommit
+				version.TrustReason = trust
+				response = append(response, version)
+			}
+		}
+
+		version := NewVersion(pr)
+		version.CommittedDate = updated.UTC()
+		if triggerComment != nil {
+			version.Comment = strconv.FormatInt(triggerComment.ID, 10)
+		}
+		version.BaseCommit = baseCommit
+		version.TrustReason = trust
+		response = append(response, version)
 	}
 
-	sort.Sort(response)
+	// Versions with the same date, such as the commits of a PR dated by their push,
+	// keep their order.
+	sort.Stable(response)
+
+	if !unsettled.IsZero() {
+		var settled CheckResponse
+		for _, version := range response {
+			if !version.CommittedDate.After(unsettled) {
+				settled = append(settled, version)
+			}
+		}
+		response = settled
+	}
 
 	// If there are no new but an old version = return the old
 	if len(response) == 0 && request.Version.PR != "" {
@@ -131,6 +511,351 @@ func ContainsSkipCI(s string) bool {
 	return re.MatchString(s)
 }
 
+// containsString returns true if the list contains the string, ignoring case.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// latestTriggerComment returns the most recent comment matching the trigger which
+// was posted by a user with write access to the repository. Permissions are cached
+// in writers.
+func latestTriggerComment(ctx context.Context, comments []*gitea.Comment, trigger *regexp.Regexp, manager Gitea, writers map[string]bool) (*gitea.Comment, error) {
+	var latest *gitea.Comment
+	for _, comment := range comments {
+		if comment.Poster == nil || !trigger.MatchString(comment.Body) {
+			continue
+		}
+		if latest != nil && !comment.Created.After(latest.Created) {
+			continue
+		}
+
+		login := strings.ToLower(comment.Poster.UserName)
+		canWrite, ok := writers[login]
+		if !ok {
+			var err error
+			canWrite, err = manager.HasWriteAccess(ctx, comment.Poster.UserName)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check permissions of %s: %s", comment.Poster.UserName, err)
+			}
+			writers[login] = canWrite
+		}
+		if canWrite {
+			latest = comment
+		}
+	}
+	return latest, nil
+}
+
+// stateFilter returns the narrowest state filter supported by the Gitea API which
+// includes all of the given states.
+func stateFilter(states []gitea.StateType) gitea.StateType {
+	var open, closed bool
+	for _, state := range states {
+		switch state {
+		case gitea.StateOpen:
+			open = true
+		case gitea.StateClosed, StateMerged, StateClosedUnmerged:
+			closed = true
+		default:
+			return gitea.StateAll
+		}
+	}
+	switch {
+	case open && closed:
+		return gitea.StateAll
+	case closed:
+		return gitea.StateClosed
+	default:
+		return gitea.StateOpen
+	}
+}
+
+// MergeableSettleTime is how long after a PR was last updated Gitea may still be
+// checking it for conflicts.
+const MergeableSettleTime = 5 * time.Minute
+
+// PaginationMargin is subtracted from the date of the previous version when listing
+// pull requests, to allow for clock skew between committers and the Gitea server.
+const PaginationMargin = time.Hour
+
+// updatedSince returns the time before which pull requests cannot have been updated
+// in a way that produces a new version, or the zero time if every pull request has
+// to be considered. This is the case when versions can be produced by events which do
+// not necessarily update the pull request, such as statuses or base branch changes.
+func updatedSince(request CheckRequest) time.Time {
+	source := request.Source
+	if request.Version.CommittedDate.IsZero() {
+		return time.Time{}
+	}
+	if len(source.RequiredStatusContexts) > 0 || source.RebuildOnBaseChange ||
+		source.RequiredReviewApprovals > 0 || source.CommentTrigger != "" {
+		return time.Time{}
+	}
+	return request.Version.CommittedDate.Add(-PaginationMargin)
+}
+
+// trustedAuthors resolves the trusted users and teams of the source into a map
+// from (lower case) user names to the reason they are trusted.
+func trustedAuthors(ctx context.Context, source Source, manager Gitea) (map[string]string, error) {
+	trusted := trustedUsers(source)
+	for _, team := range source.TrustedTeams {
+		org, name, err := parseTeam(team)
+		if err != nil {
+			return nil, err
+		}
+		members, err := manager.ListTeamMembers(ctx, org, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list members of team %s: %s", team, err)
+		}
+		for _, member := range members {
+			if _, ok := trusted[strings.ToLower(member)]; !ok {
+				trusted[strings.ToLower(member)] = "trusted_team:" + team
+			}
+		}
+	}
+	return trusted, nil
+}
+
+// trustedUsers returns the trusted users of the source, without resolving the
+// trusted teams.
+func trustedUsers(source Source) map[string]string {
+	trusted := make(map[string]string)
+	for _, user := range source.TrustedUsers {
+		trusted[strings.ToLower(user)] = "trusted_user"
+	}
+	return trusted
+}
+
+// trustReason returns why the PR may be built, or an empty string if its author is
+// not trusted and the PR has not been labelled as ok to test.
+func trustReason(source Source, pr *PullRequest, trusted map[string]string) string {
+	if len(source.TrustedUsers) == 0 && len(source.TrustedTeams) == 0 {
+		return "unrestricted"
+	}
+	if pr.Poster != nil {
+		if reason, ok := trusted[strings.ToLower(pr.Poster.UserName)]; ok {
+			return reason
+		}
+	}
+	for _, label := range pr.Labels {
+		if label.Name == source.TrustLabel() {
+			return "ok_to_test"
+		}
+	}
+	return ""
+}
+
+// ApprovalDate returns the time at which the required number of current approvals
+// was reached, and false if the reviews do not contain enough approvals. Only the
+// latest approving or change requesting review of each reviewer is considered,
+// and stale or dismissed reviews do not count.
+func ApprovalDate(reviews []*gitea.PullReview, required int, officialOnly bool) (time.Time, bool) {
+	latest := make(map[string]*gitea.PullReview)
+	for _, review := range reviews {
+		if review.State != gitea.ReviewStateApproved && review.State != gitea.ReviewStateRequestChanges {
+			continue
+		}
+		var reviewer string
+		if review.Reviewer != nil {
+			reviewer = review.Reviewer.UserName
+		}
+		if previous, ok := latest[reviewer]; ok && previous.Submitted.After(review.Submitted) {
+			continue
+		}
+		latest[reviewer] = review
+	}
+
+	var approvals []time.Time
+	for _, review := range latest {
+		if review.State != gitea.ReviewStateApproved || review.Stale || review.Dismissed {
+			continue
+		}
+		if officialOnly && !review.Official {
+			continue
+		}
+		approvals = append(approvals, review.Submitted)
+	}
+
+	if required <= 0 || len(approvals) < required {
+		return time.Time{}, false
+	}
+	sort.Slice(approvals, func(i, j int) bool {
+		return approvals[i].Before(approvals[j])
+	})
+	return approvals[required-1], true
+}
+
+// PushDate returns the time of the last push to the pull request in the timeline, or
+// the zero time if there is none.
+func PushDate(events []*TimelineEvent) time.Time {
+	var pushed time.Time
+	for _, event := range events {
+		if event.Type == TimelineEventPush && event.Created.After(pushed) {
+			pushed = event.Created
+		}
+	}
+	return pushed
+}
+
+// pushContent is the body of a push in the timeline of a pull request.
+type pushContent struct {
+	IsForcePush bool     `json:"is_force_push"`
+	CommitIDs   []string `json:"commit_ids"`
+}
+
+// PushedCommits returns the commits pushed to the pull request after the given time
+// according to its timeline. Force pushes only record the heads before and after the
+// push, so the head before the first force push since then is returned as well, or
+// an empty string if there was none.
+func PushedCommits(events []*TimelineEvent, since time.Time) (map[string]bool, string) {
+	pushed := make(map[string]bool)
+	var forcedFrom string
+	var forced time.Time
+	for _, event := range events {
+		if event.Type != TimelineEventPush || !event.Created.After(since) {
+			continue
+		}
+		var content pushContent
+		if err := json.Unmarshal([]byte(event.Body), &content); err != nil {
+			continue
+		}
+		if !content.IsForcePush {
+			for _, id := range content.CommitIDs {
+				pushed[id] = true
+			}
+			continue
+		}
+		if len(content.CommitIDs) > 0 && (forced.IsZero() || event.Created.Before(forced)) {
+			forcedFrom = content.CommitIDs[0]
+			forced = event.Created
+		}
+	}
+	return pushed, forcedFrom
+}
+
+// ReadyDate returns the time the pull request last left work in progress, i.e. its
+// title was changed to no longer start with one of the prefixes, or the zero time if
+// there is no such title change in the timeline.
+func ReadyDate(events []*TimelineEvent, prefixes []string) time.Time {
+	var ready time.Time
+	for _, event := range events {
+		if event.Type != TimelineEventChangeTitle || !event.Created.After(ready) {
+			continue
+		}
+		if isDraftTitle(event.OldTitle, prefixes) && !isDraftTitle(event.NewTitle, prefixes) {
+			ready = event.Created
+		}
+	}
+	return ready
+}
+
+// LabelDate returns the time the label was last added to the pull request, and false
+// if it was not added after the last push to it. Gitea records added labels with a body
+// of "1".
+func LabelDate(events []*TimelineEvent, label string) (time.Time, bool) {
+	var labelled time.Time
+	for _, event := range events {
+		if event.Type != TimelineEventLabel || event.Label == nil || event.Body != "1" {
+			continue
+		}
+		if event.Label.Name == label && event.Created.After(labelled) {
+			labelled = event.Created
+		}
+	}
+	if labelled.IsZero() || !labelled.After(PushDate(events)) {
+		return time.Time{}, false
+	}
+	return labelled, true
+}
+
+// MatchLabel returns true if the label matches the pattern. Patterns enclosed in
+// slashes (e.g. /^area\/.+$/) are regular expressions, all other patterns use the
+// syntax of path.Match (e.g. area/*).
+func MatchLabel(pattern, label string) (bool, error) {
+	if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return false, err
+		}
+		return re.MatchString(label), nil
+	}
+	return path.Match(pattern, label)
+}
+
+// MatchAuthor returns true if the login matches one of the patterns. Like Gitea
+// user names, the comparison is case insensitive. Patterns use the syntax of
+// path.Match, e.g. renovate*.
+func MatchAuthor(patterns []string, login string) (bool, error) {
+	for _, pattern := range patterns {
+		match, err := path.Match(strings.ToLower(pattern), strings.ToLower(login))
+		if err != nil {
+			return false, err
+		}
+		if match {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// MatchBranch returns true if the branch matches at least one of the patterns (or
+// only negated patterns are given) and none of the patterns prefixed with "!".
+// Patterns use the syntax of path.Match, e.g. release/*.
+func MatchBranch(patterns []string, branch string) (bool, error) {
+	included := true
+	for _, pattern := range patterns {
+		if !strings.HasPrefix(pattern, "!") {
+			included = false
+			break
+		}
+	}
+
+	for _, pattern := range patterns {
+		negated := strings.HasPrefix(pattern, "!")
+		match, err := path.Match(strings.TrimPrefix(pattern, "!"), branch)
+		if err != nil {
+			return false, err
+		}
+		if match && negated {
+			return false, nil
+		}
+		if match {
+			included = true
+		}
+	}
+	return included, nil
+}
+
+// StatusDate returns the time at which the last of the required status contexts
+// succeeded, and false if any of them is missing or has not succeeded. Only the
+// latest status of each context is considered.
+func StatusDate(statuses []*gitea.Status, contexts []string) (time.Time, bool) {
+	latest := make(map[string]*gitea.Status)
+	for _, status := range statuses {
+		if previous, ok := latest[status.Context]; ok && previous.ID > status.ID {
+			continue
+		}
+		latest[status.Context] = status
+	}
+
+	var succeeded time.Time
+	for _, context := range contexts {
+		status, ok := latest[context]
+		if !ok || status.State != gitea.StatusSuccess {
+			return time.Time{}, false
+		}
+		if status.Updated.After(succeeded) {
+			succeeded = status.Updated
+		}
+	}
+	return succeeded, true
+}
+
 func FilterIgnorePath(files []string, pattern string) ([]string, error) {
 	var out []string
 	for _, file := range files {
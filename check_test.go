@@ -1,7 +1,9 @@
 package resource_test
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"code.gitea.io/sdk/gitea"
 	resource "github.com/hur/gitea-pr-resource"
@@ -22,15 +24,169 @@ var (
 		createTestPR(9, "master", false, false, nil, false, gitea.StateOpen),
 		createTestPR(10, "master", false, false, nil, false, gitea.StateClosed),
 		createTestPR(11, "master", false, false, nil, false, gitea.StateOpen),
+		createTestPR(12, "master", false, false, []string{"area/ci", "enhancement"}, false, gitea.StateOpen),
 	}
 )
 
 func TestCheck(t *testing.T) {
+	// A PR that dropped its WIP prefix after the last commit was pushed, and was
+	// commented on since.
+	readyTime := time.Now().Add(-1 * time.Hour)
+	readyPullRequest := createTestPR(13, "master", false, false, nil, false, gitea.StateOpen)
+	readyPullRequest.Updated = ptr(time.Now())
+	readyVersion := resource.NewVersion(readyPullRequest)
+	readyVersion.CommittedDate = readyTime.UTC()
+	readyTimeline := []*resource.TimelineEvent{
+		{Type: resource.TimelineEventChangeTitle, Created: readyTime, OldTitle: "WIP: pr13 title", NewTitle: "pr13 title"},
+		{Type: "comment", Created: time.Now()},
+	}
+
+	// A PR whose head was force pushed to a commit older than the previous version,
+	// and which was commented on since.
+	pushTime := time.Now().Add(-1 * time.Hour)
+	pushedPullRequest := createTestPR(17, "master", false, false, nil, false, gitea.StateOpen)
+	pushedPullRequest.Updated = ptr(time.Now())
+	pushedVersion := resource.NewVersion(pushedPullRequest)
+	pushedVersion.CommittedDate = pushTime.UTC()
+	pushedTimeline := []*resource.TimelineEvent{
+		{Type: resource.TimelineEventPush, Created: pushTime},
+		{Type: "comment", Created: time.Now()},
+	}
+
+	// A PR whose tip was committed with a clock set a day ahead.
+	skewedPullRequest := createTestPR(19, "master", false, false, nil, false, gitea.StateOpen)
+	skewedPullRequest.Tip.Created = time.Now().Add(24 * time.Hour)
+	skewedPullRequest.Updated = ptr(time.Now().Add(-1 * time.Minute))
+	skewedVersion := resource.NewVersion(skewedPullRequest)
+	skewedVersion.CommittedDate = skewedPullRequest.Updated.UTC()
+	// A PR pushed to after the version of the skewed PR.
+	laterPushTime := time.Now()
+	laterPushedPullRequest := createTestPR(20, "master", false, false, nil, false, gitea.StateOpen)
+	laterPushedPullRequest.Updated = ptr(laterPushTime)
+	laterPushedVersion := resource.NewVersion(laterPushedPullRequest)
+	laterPushedVersion.CommittedDate = laterPushTime.UTC()
+
+	plannedPullRequest := createTestPR(15, "master", false, false, nil, false, gitea.StateOpen)
+	plannedPullRequest.Milestone = &gitea.Milestone{Title: "v1.0"}
+	plannedPullRequest.Assignees = []*gitea.User{{UserName: "reviewer1"}, {UserName: "reviewer2"}}
+
+	conflictingPullRequest := createTestPR(0, "master", false, false, nil, false, gitea.StateOpen)
+	conflictingPullRequest.Mergeable = false
+	conflictingPullRequest.Updated = ptr(time.Now().Add(-1 * time.Hour))
+
+	// A PR which was just pushed to, and may still be checked for conflicts.
+	checkingPullRequest := createTestPR(18, "master", false, false, nil, false, gitea.StateOpen)
+	checkingPullRequest.Tip.Created = testPullRequests[2].Tip.Created
+	checkingPullRequest.Updated = ptr(time.Now())
+	checkingPullRequest.Mergeable = false
+	checkedPullRequest := *checkingPullRequest
+	checkedPullRequest.Mergeable = true
+
+	// Gitea reports WIP PRs as unmergeable.
+	wipPullRequest := createTestPR(2, "master", false, false, nil, true, gitea.StateOpen)
+	wipPullRequest.Updated = ptr(time.Now().Add(-1 * time.Hour))
+	wipPullRequest.Mergeable = false
+
+	commentTime := time.Now()
+	commentVersion := resource.NewVersion(testPullRequests[1])
+	commentVersion.CommittedDate = commentTime.UTC()
+	commentVersion.Comment = "42"
+
+	baseTime := time.Now()
+	baseVersion := resource.NewVersion(testPullRequests[1])
+	baseVersion.CommittedDate = baseTime.UTC()
+	baseVersion.BaseCommit = "base2"
+
+	// The base was reset to a commit older than the previous version.
+	resetBaseVersion := baseVersion
+	resetBaseVersion.CommittedDate = baseVersion.CommittedDate.Add(time.Second)
+	resetBaseVersion.BaseCommit = "base1"
+	movedBaseVersion := func(pr *resource.PullRequest) resource.Version {
+		version := resource.NewVersion(pr)
+		version.CommittedDate = resetBaseVersion.CommittedDate
+		version.BaseCommit = "base1"
+		return version
+	}
+
+	intermediateCommit := &gitea.Commit{
+		CommitMeta: &gitea.CommitMeta{
+			SHA:     "intermediate",
+			Created: testPullRequests[1].Tip.Created.Add(-1 * time.Hour),
+		},
+	}
+	intermediateVersion := resource.NewVersion(testPullRequests[1])
+	intermediateVersion.Commit = "intermediate"
+	intermediateVersion.CommittedDate = intermediateCommit.Created.UTC()
+
+	// Commits made before the previous version of the same PR, but pushed after it, are
+	// dated like the tip.
+	previousCommit := &gitea.Commit{
+		CommitMeta: &gitea.CommitMeta{
+			SHA:     "previous",
+			Created: testPullRequests[1].Tip.Created.Add(-2 * time.Hour),
+		},
+	}
+	// The commits of PR 2 were pushed in two parts, the first before the previous version
+	// of PR 3.
+	oldCommit := &gitea.Commit{
+		CommitMeta: &gitea.CommitMeta{
+			SHA:     "old",
+			Created: testPullRequests[3].Tip.Created,
+		},
+	}
+	intermediateTimeline := []*resource.TimelineEvent{
+		{Type: resource.TimelineEventPush, Created: oldCommit.Created, Body: `{"is_force_push":false,"commit_ids":["old"]}`},
+		{Type: resource.TimelineEventPush, Created: testPullRequests[1].Tip.Created, Body: `{"is_force_push":false,"commit_ids":["oid2","intermediate"]}`},
+	}
+	previousCommitVersion := resource.NewVersion(testPullRequests[1])
+	previousCommitVersion.Commit = "previous"
+	previousCommitVersion.CommittedDate = testPullRequests[1].Tip.Created.Add(-30 * time.Minute).UTC()
+	pushedIntermediateVersion := intermediateVersion
+	pushedIntermediateVersion.CommittedDate = testPullRequests[1].Tip.Created.UTC()
+
+	// A PR whose head was force pushed, keeping the original commit dates.
+	forcePushedPullRequest := createTestPR(16, "master", false, false, nil, false, gitea.StateOpen)
+	forcePushedPullRequest.Head.Sha = "rebased"
+	forcePushedPreviousVersion := resource.NewVersion(forcePushedPullRequest)
+	forcePushedPreviousVersion.Commit = "original"
+	forcePushedVersion := resource.NewVersion(forcePushedPullRequest)
+	forcePushedVersion.CommittedDate = forcePushedPreviousVersion.CommittedDate.Add(time.Second)
+
+	trustedVersion := func(pr *resource.PullRequest, reason string) resource.Version {
+		version := resource.NewVersion(pr)
+		version.TrustReason = reason
+		return version
+	}
+	// An untrusted PR which was labelled as ok to test after its last push, and is
+	// dated by the label.
+	okToTestLabel := &gitea.Label{Name: "wontfix"}
+	labelTime := time.Now().Add(-1 * time.Hour)
+	labelledTimeline := []*resource.TimelineEvent{
+		{Type: resource.TimelineEventPush, Created: time.Now().Add(-2 * time.Hour)},
+		{Type: resource.TimelineEventLabel, Created: labelTime, Body: "1", Label: okToTestLabel},
+	}
+	labelledVersion := trustedVersion(testPullRequests[7], "ok_to_test")
+	labelledVersion.CommittedDate = labelTime.UTC()
+	pushedSinceLabelTimeline := []*resource.TimelineEvent{
+		{Type: resource.TimelineEventLabel, Created: time.Now().Add(-2 * time.Hour), Body: "1", Label: okToTestLabel},
+		{Type: resource.TimelineEventPush, Created: time.Now().Add(-1 * time.Hour)},
+	}
+
+	unmergedPullRequest := createTestPR(14, "master", false, false, nil, false, gitea.StateClosed)
+	unmergedPullRequest.HasMerged = false
+
 	tests := []struct {
 		description  string
 		source       resource.Source
 		version      resource.Version
 		files        [][]string
+		reviews      [][]*gitea.PullReview
+		teamMembers  []string
+		statuses     [][]*gitea.Status
+		comments     [][]*gitea.Comment
+		baseBranch   *gitea.Branch
+		commits      [][]*gitea.Commit
+		timelines    [][]*resource.TimelineEvent
 		pullRequests []*resource.PullRequest
 		expected     resource.CheckResponse
 	}{
@@ -144,11 +300,11 @@ func TestCheck(t *testing.T) {
 		},
 
 		{
-			description: "check returns latest version from a PR with at least one of the desired labels on it",
+			description: "check supports base branch patterns",
 			source: resource.Source{
-				Repository:  "itsdalmo/test-repository",
-				AccessToken: "oauthtoken",
-				Labels:      []string{"enhancement"},
+				Repository:   "itsdalmo/test-repository",
+				AccessToken:  "oauthtoken",
+				BaseBranches: []string{"dev*"},
 			},
 			version:      resource.Version{},
 			pullRequests: testPullRequests,
@@ -159,120 +315,1256 @@ func TestCheck(t *testing.T) {
 		},
 
 		{
-			description: "check returns latest version from a PR with a single state filter",
+			description: "check supports negated head branch patterns",
+			source: resource.Source{
+				Repository:   "itsdalmo/test-repository",
+				AccessToken:  "oauthtoken",
+				HeadBranches: []string{"!pr2", "!pr3"},
+			},
+			version:      resource.NewVersion(testPullRequests[4]),
+			pullRequests: testPullRequests,
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				resource.NewVersion(testPullRequests[3]),
+			},
+		},
+
+		{
+			description: "check only returns versions for PRs by the specified authors",
 			source: resource.Source{
 				Repository:  "itsdalmo/test-repository",
 				AccessToken: "oauthtoken",
-				State:       gitea.StateClosed,
+				Authors:     []string{"LOGIN3", "login1?"},
 			},
-			version:      resource.Version{},
+			version:      resource.NewVersion(testPullRequests[11]),
 			pullRequests: testPullRequests,
 			files:        [][]string{},
 			expected: resource.CheckResponse{
-				resource.NewVersion(testPullRequests[9]),
+				resource.NewVersion(testPullRequests[10]),
+				resource.NewVersion(testPullRequests[2]),
 			},
 		},
 
 		{
-			description: "check filters out versions from a PR which do not match the state filter",
+			description: "check skips PRs by ignored authors",
+			source: resource.Source{
+				Repository:    "itsdalmo/test-repository",
+				AccessToken:   "oauthtoken",
+				IgnoreAuthors: []string{"login[23]"},
+			},
+			version:      resource.NewVersion(testPullRequests[4]),
+			pullRequests: testPullRequests,
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				resource.NewVersion(testPullRequests[3]),
+			},
+		},
+
+		{
+			description: "check only returns versions for PRs in the specified milestones",
 			source: resource.Source{
 				Repository:  "itsdalmo/test-repository",
 				AccessToken: "oauthtoken",
-				State:       gitea.StateOpen,
+				Milestones:  []string{"v1.0"},
 			},
 			version:      resource.Version{},
-			pullRequests: testPullRequests[9:10],
+			pullRequests: append([]*resource.PullRequest{plannedPullRequest}, testPullRequests...),
 			files:        [][]string{},
-			expected:     resource.CheckResponse(nil),
+			expected: resource.CheckResponse{
+				resource.NewVersion(plannedPullRequest),
+			},
 		},
-	}
 
-	for _, tc := range tests {
-		t.Run(tc.description, func(t *testing.T) {
-			fakeGitea := new(fakes.FakeGitea)
-			pullRequests := []*resource.PullRequest{}
-			filterStates := gitea.StateOpen
-			if tc.source.State != "" {
-				filterStates = tc.source.State
-			}
-			if filterStates != gitea.StateAll {
-				for i := range tc.pullRequests {
-					if filterStates == tc.pullRequests[i].PullRequest.State {
-						pullRequests = append(pullRequests, tc.pullRequests[i])
-					}
-				}
-			} else {
-				pullRequests = tc.pullRequests
-			}
-			fakeGitea.ListPullRequestsReturns(pullRequests, nil)
+		{
+			description: "check only returns versions for PRs assigned to the specified users",
+			source: resource.Source{
+				Repository:  "itsdalmo/test-repository",
+				AccessToken: "oauthtoken",
+				Assignees:   []string{"Reviewer2"},
+			},
+			version:      resource.Version{},
+			pullRequests: append([]*resource.PullRequest{plannedPullRequest}, testPullRequests...),
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				resource.NewVersion(plannedPullRequest),
+			},
+		},
 
-			for i, file := range tc.files {
-				fakeGitea.ListModifiedFilesReturnsOnCall(i, file, nil)
-			}
+		{
+			description: "check skips PRs which conflict with the base when skip_unmergeable is set",
+			source: resource.Source{
+				Repository:      "itsdalmo/test-repository",
+				AccessToken:     "oauthtoken",
+				SkipUnmergeable: true,
+			},
+			version:      resource.Version{},
+			pullRequests: append([]*resource.PullRequest{conflictingPullRequest}, testPullRequests...),
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				resource.NewVersion(testPullRequests[1]),
+			},
+		},
 
-			input := resource.CheckRequest{Source: tc.source, Version: tc.version}
-			output, err := resource.Check(input, fakeGitea)
+		{
+			description: "check holds back versions dated after PRs which may still be checked for conflicts",
+			source: resource.Source{
+				Repository:      "itsdalmo/test-repository",
+				AccessToken:     "oauthtoken",
+				SkipUnmergeable: true,
+			},
+			version:      resource.NewVersion(testPullRequests[3]),
+			pullRequests: []*resource.PullRequest{checkingPullRequest, testPullRequests[1]},
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				resource.NewVersion(testPullRequests[3]),
+			},
+		},
 
-			if assert.NoError(t, err) {
-				assert.Equal(t, tc.expected, output)
-			}
-			assert.Equal(t, 1, fakeGitea.ListPullRequestsCallCount())
-		})
-	}
-}
+		{
+			description: "check returns PRs which became mergeable along with the versions held back",
+			source: resource.Source{
+				Repository:      "itsdalmo/test-repository",
+				AccessToken:     "oauthtoken",
+				SkipUnmergeable: true,
+			},
+			version:      resource.NewVersion(testPullRequests[3]),
+			pullRequests: []*resource.PullRequest{&checkedPullRequest, testPullRequests[1]},
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				resource.NewVersion(&checkedPullRequest),
+				resource.NewVersion(testPullRequests[1]),
+			},
+		},
 
-func TestContainsSkipCI(t *testing.T) {
-	tests := []struct {
-		description string
-		message     string
-		want        bool
-	}{
 		{
-			description: "does not just match any symbol in the regexp",
-			message:     "(",
-			want:        false,
+			description: "check does not skip WIP PRs as unmergeable",
+			source: resource.Source{
+				Repository:      "itsdalmo/test-repository",
+				AccessToken:     "oauthtoken",
+				SkipUnmergeable: true,
+			},
+			version:      resource.NewVersion(testPullRequests[3]),
+			pullRequests: []*resource.PullRequest{wipPullRequest},
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				resource.NewVersion(wipPullRequest),
+			},
 		},
+
 		{
-			description: "does not match when it should not",
-			message:     "test",
-			want:        false,
+			description: "check returns latest version from a PR with at least one of the desired labels on it",
+			source: resource.Source{
+				Repository:  "itsdalmo/test-repository",
+				AccessToken: "oauthtoken",
+				Labels:      []string{"enhancement"},
+			},
+			version:      resource.Version{},
+			pullRequests: testPullRequests,
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				resource.NewVersion(testPullRequests[6]),
+			},
 		},
+
 		{
-			description: "matches [ci skip]",
-			message:     "[ci skip]",
-			want:        true,
+			description: "check skips PRs from forks when disable_forks is set",
+			source: resource.Source{
+				Repository:   "itsdalmo/test-repository",
+				AccessToken:  "oauthtoken",
+				DisableForks: true,
+			},
+			version:      resource.NewVersion(testPullRequests[5]),
+			pullRequests: testPullRequests,
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				resource.NewVersion(testPullRequests[3]),
+				resource.NewVersion(testPullRequests[2]),
+				resource.NewVersion(testPullRequests[1]),
+			},
 		},
+
 		{
-			description: "matches [skip ci]",
-			message:     "[skip ci]",
-			want:        true,
+			description: "check only returns PRs from forks when forks_only is set",
+			source: resource.Source{
+				Repository:  "itsdalmo/test-repository",
+				AccessToken: "oauthtoken",
+				ForksOnly:   true,
+			},
+			version:      resource.Version{},
+			pullRequests: testPullRequests,
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				resource.NewVersion(testPullRequests[4]),
+			},
 		},
+
 		{
-			description: "matches [no ci]",
-			message:     "[no ci]",
-			want:        true,
+			description: "check skips work in progress PRs when ignore_drafts is set",
+			source: resource.Source{
+				Repository:   "itsdalmo/test-repository",
+				AccessToken:  "oauthtoken",
+				IgnoreDrafts: true,
+			},
+			version:      resource.NewVersion(testPullRequests[3]),
+			pullRequests: testPullRequests,
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				resource.NewVersion(testPullRequests[1]),
+			},
 		},
+
 		{
-			description: "matches trailing skip ci",
-			message:     "trailing [skip ci]",
-			want:        true,
+			description: "check supports custom draft prefixes",
+			source: resource.Source{
+				Repository:    "itsdalmo/test-repository",
+				AccessToken:   "oauthtoken",
+				IgnoreDrafts:  true,
+				DraftPrefixes: []string{"pr2"},
+			},
+			version:      resource.NewVersion(testPullRequests[3]),
+			pullRequests: testPullRequests,
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				resource.NewVersion(testPullRequests[2]),
+			},
 		},
+
 		{
-			description: "matches leading skip ci",
-			message:     "[skip ci] leading",
-			want:        true,
+			description: "check returns a new version when a PR leaves work in progress",
+			source: resource.Source{
+				Repository:   "itsdalmo/test-repository",
+				AccessToken:  "oauthtoken",
+				IgnoreDrafts: true,
+			},
+			version:      resource.NewVersion(testPullRequests[1]),
+			pullRequests: append([]*resource.PullRequest{readyPullRequest}, testPullRequests...),
+			timelines:    [][]*resource.TimelineEvent{readyTimeline},
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				readyVersion,
+			},
 		},
+
 		{
-			description: "is case insensitive",
-			message:     "case[Skip CI]insensitive",
-			want:        true,
+			description: "check does not return a new version for updates after a PR left work in progress",
+			source: resource.Source{
+				Repository:   "itsdalmo/test-repository",
+				AccessToken:  "oauthtoken",
+				IgnoreDrafts: true,
+			},
+			version:      readyVersion,
+			pullRequests: append([]*resource.PullRequest{readyPullRequest}, testPullRequests...),
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				readyVersion,
+			},
 		},
-	}
 
-	for _, tc := range tests {
-		t.Run(tc.description, func(t *testing.T) {
-			got := resource.ContainsSkipCI(tc.message)
-			assert.Equal(t, tc.want, got)
+		{
+			description: "check only returns versions for PRs with the required approvals",
+			source: resource.Source{
+				Repository:              "itsdalmo/test-repository",
+				AccessToken:             "oauthtoken",
+				RequiredReviewApprovals: 1,
+			},
+			version:      resource.NewVersion(testPullRequests[3]),
+			pullRequests: testPullRequests,
+			reviews: [][]*gitea.PullReview{
+				{},
+				{createTestReview("reviewer1", gitea.ReviewStateApproved, false, time.Time{})},
+				{createTestReview("reviewer1", gitea.ReviewStateApproved, true, time.Time{})},
+			},
+			expected: resource.CheckResponse{
+				resource.NewVersion(testPullRequests[2]),
+			},
+		},
+
+		{
+			description: "check only returns versions for PRs where the required statuses succeeded",
+			source: resource.Source{
+				Repository:             "itsdalmo/test-repository",
+				AccessToken:            "oauthtoken",
+				RequiredStatusContexts: []string{"lint", "ci/unit"},
+			},
+			version:      resource.NewVersion(testPullRequests[3]),
+			pullRequests: testPullRequests,
+			statuses: [][]*gitea.Status{
+				{createTestStatus(1, "lint", gitea.StatusSuccess)},
+				{createTestStatus(1, "lint", gitea.StatusSuccess), createTestStatus(2, "ci/unit", gitea.StatusSuccess)},
+			},
+			expected: resource.CheckResponse{
+				resource.NewVersion(testPullRequests[2]),
+			},
+		},
+
+		{
+			description: "check returns a new version when a trigger comment is posted",
+			source: resource.Source{
+				Repository:     "itsdalmo/test-repository",
+				AccessToken:    "oauthtoken",
+				CommentTrigger: "^/retest",
+			},
+			version:      resource.NewVersion(testPullRequests[1]),
+			pullRequests: testPullRequests,
+			comments: [][]*gitea.Comment{
+				{
+					{ID: 41, Poster: &gitea.User{UserName: "maintainer"}, Body: "looks good", Created: commentTime},
+					{ID: 42, Poster: &gitea.User{UserName: "maintainer"}, Body: "/retest", Created: commentTime},
+					{ID: 43, Poster: &gitea.User{UserName: "outsider"}, Body: "/retest", Created: commentTime.Add(time.Minute)},
+				},
+			},
+			expected: resource.CheckResponse{
+				commentVersion,
+			},
+		},
+
+		{
+			description: "check ignores trigger comments from users without write access",
+			source: resource.Source{
+				Repository:     "itsdalmo/test-repository",
+				AccessToken:    "oauthtoken",
+				CommentTrigger: "^/retest",
+			},
+			version:      resource.NewVersion(testPullRequests[1]),
+			pullRequests: testPullRequests,
+			comments: [][]*gitea.Comment{
+				{
+					{ID: 43, Poster: &gitea.User{UserName: "outsider"}, Body: "/retest", Created: commentTime},
+				},
+			},
+			expected: resource.CheckResponse{
+				resource.NewVersion(testPullRequests[1]),
+			},
+		},
+
+		{
+			description: "check returns a new version pinned to the base when the base advances",
+			source: resource.Source{
+				Repository:          "itsdalmo/test-repository",
+				AccessToken:         "oauthtoken",
+				RebuildOnBaseChange: true,
+			},
+			version:      resource.NewVersion(testPullRequests[1]),
+			pullRequests: testPullRequests[1:2],
+			baseBranch:   &gitea.Branch{Name: "master", Commit: &gitea.PayloadCommit{ID: "base2", Timestamp: baseTime}},
+			expected: resource.CheckResponse{
+				baseVersion,
+			},
+		},
+
+		{
+			description: "check returns a new version when the base moves to an older commit",
+			source: resource.Source{
+				Repository:          "itsdalmo/test-repository",
+				AccessToken:         "oauthtoken",
+				RebuildOnBaseChange: true,
+			},
+			version:      baseVersion,
+			pullRequests: testPullRequests[1:2],
+			baseBranch:   &gitea.Branch{Name: "master", Commit: &gitea.PayloadCommit{ID: "base1", Timestamp: baseTime.Add(-1 * time.Hour)}},
+			expected: resource.CheckResponse{
+				resetBaseVersion,
+			},
+		},
+
+		{
+			description: "check returns new versions of all PRs on the base when it moves to an older commit",
+			source: resource.Source{
+				Repository:          "itsdalmo/test-repository",
+				AccessToken:         "oauthtoken",
+				RebuildOnBaseChange: true,
+			},
+			version:      baseVersion,
+			pullRequests: []*resource.PullRequest{testPullRequests[1], testPullRequests[3], testPullRequests[6]},
+			baseBranch:   &gitea.Branch{Name: "master", Commit: &gitea.PayloadCommit{ID: "base1", Timestamp: baseTime.Add(-1 * time.Hour)}},
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				movedBaseVersion(testPullRequests[1]),
+				movedBaseVersion(testPullRequests[3]),
+			},
+		},
+
+		{
+			description: "check does not return a new version when the base is unchanged",
+			source: resource.Source{
+				Repository:          "itsdalmo/test-repository",
+				AccessToken:         "oauthtoken",
+				RebuildOnBaseChange: true,
+			},
+			version:      baseVersion,
+			pullRequests: testPullRequests[1:2],
+			baseBranch:   &gitea.Branch{Name: "master", Commit: &gitea.PayloadCommit{ID: "base2", Timestamp: baseTime}},
+			expected: resource.CheckResponse{
+				baseVersion,
+			},
+		},
+
+		{
+			description: "check returns a version for every new commit when every_commit is set",
+			source: resource.Source{
+				Repository:  "itsdalmo/test-repository",
+				AccessToken: "oauthtoken",
+				EveryCommit: true,
+			},
+			version:      resource.NewVersion(testPullRequests[2]),
+			pullRequests: testPullRequests[1:3],
+			commits: [][]*gitea.Commit{
+				{
+					&testPullRequests[1].Tip,
+					intermediateCommit,
+				},
+			},
+			timelines: [][]*resource.TimelineEvent{intermediateTimeline},
+			expected: resource.CheckResponse{
+				intermediateVersion,
+				resource.NewVersion(testPullRequests[1]),
+			},
+		},
+
+		{
+			description: "check does not return commits of other PRs pushed before the previous version",
+			source: resource.Source{
+				Repository:  "itsdalmo/test-repository",
+				AccessToken: "oauthtoken",
+				EveryCommit: true,
+			},
+			version:      resource.NewVersion(testPullRequests[2]),
+			pullRequests: testPullRequests[1:3],
+			commits: [][]*gitea.Commit{
+				{
+					&testPullRequests[1].Tip,
+					intermediateCommit,
+					oldCommit,
+				},
+			},
+			timelines: [][]*resource.TimelineEvent{intermediateTimeline},
+			expected: resource.CheckResponse{
+				intermediateVersion,
+				resource.NewVersion(testPullRequests[1]),
+			},
+		},
+
+		{
+			description: "check returns all commits of other PRs rebased since the previous version",
+			source: resource.Source{
+				Repository:  "itsdalmo/test-repository",
+				AccessToken: "oauthtoken",
+				EveryCommit: true,
+			},
+			version:      resource.NewVersion(testPullRequests[2]),
+			pullRequests: testPullRequests[1:3],
+			commits: [][]*gitea.Commit{
+				{
+					&testPullRequests[1].Tip,
+					intermediateCommit,
+				},
+			},
+			timelines: [][]*resource.TimelineEvent{
+				{{Type: resource.TimelineEventPush, Created: testPullRequests[1].Tip.Created, Body: `{"is_force_push":true,"commit_ids":["old","oid2"]}`}},
+			},
+			expected: resource.CheckResponse{
+				intermediateVersion,
+				resource.NewVersion(testPullRequests[1]),
+			},
+		},
+
+		{
+			description: "check returns commits pushed after the previous version even if they are older",
+			source: resource.Source{
+				Repository:  "itsdalmo/test-repository",
+				AccessToken: "oauthtoken",
+				EveryCommit: true,
+			},
+			version:      previousCommitVersion,
+			pullRequests: testPullRequests[1:2],
+			commits: [][]*gitea.Commit{
+				{
+					&testPullRequests[1].Tip,
+					intermediateCommit,
+					previousCommit,
+					&testPullRequests[3].Tip,
+				},
+			},
+			expected: resource.CheckResponse{
+				pushedIntermediateVersion,
+				resource.NewVersion(testPullRequests[1]),
+			},
+		},
+
+		{
+			description: "check only returns versions for PRs by trusted users",
+			source: resource.Source{
+				Repository:   "itsdalmo/test-repository",
+				AccessToken:  "oauthtoken",
+				TrustedUsers: []string{"login2"},
+			},
+			version:      resource.NewVersion(testPullRequests[3]),
+			pullRequests: testPullRequests,
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				trustedVersion(testPullRequests[1], "trusted_user"),
+			},
+		},
+
+		{
+			description: "check only returns versions for PRs by members of trusted teams",
+			source: resource.Source{
+				Repository:   "itsdalmo/test-repository",
+				AccessToken:  "oauthtoken",
+				TrustedTeams: []string{"itsdalmo/maintainers"},
+			},
+			version:      resource.NewVersion(testPullRequests[3]),
+			pullRequests: testPullRequests,
+			teamMembers:  []string{"login3"},
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				trustedVersion(testPullRequests[2], "trusted_team:itsdalmo/maintainers"),
+			},
+		},
+
+		{
+			description: "check returns versions for untrusted PRs with the ok-to-test label",
+			source: resource.Source{
+				Repository:    "itsdalmo/test-repository",
+				AccessToken:   "oauthtoken",
+				TrustedUsers:  []string{"login2"},
+				OkToTestLabel: "wontfix",
+			},
+			version:      resource.NewVersion(testPullRequests[8]),
+			pullRequests: testPullRequests,
+			timelines:    [][]*resource.TimelineEvent{labelledTimeline},
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				trustedVersion(testPullRequests[1], "trusted_user"),
+				labelledVersion,
+			},
+		},
+
+		{
+			description: "check skips untrusted PRs pushed to since the ok-to-test label was applied",
+			source: resource.Source{
+				Repository:    "itsdalmo/test-repository",
+				AccessToken:   "oauthtoken",
+				TrustedUsers:  []string{"login2"},
+				OkToTestLabel: "wontfix",
+			},
+			version:      resource.NewVersion(testPullRequests[8]),
+			pullRequests: testPullRequests,
+			timelines:    [][]*resource.TimelineEvent{pushedSinceLabelTimeline},
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				trustedVersion(testPullRequests[1], "trusted_user"),
+			},
+		},
+
+		{
+			description: "check skips PRs with any of the ignored labels",
+			source: resource.Source{
+				Repository:   "itsdalmo/test-repository",
+				AccessToken:  "oauthtoken",
+				IgnoreLabels: []string{"wontfix"},
+			},
+			version:      resource.NewVersion(testPullRequests[8]),
+			pullRequests: testPullRequests[6:9],
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				resource.NewVersion(testPullRequests[6]),
+			},
+		},
+
+		{
+			description: "check supports glob label patterns",
+			source: resource.Source{
+				Repository:  "itsdalmo/test-repository",
+				AccessToken: "oauthtoken",
+				Labels:      []string{"area/*"},
+			},
+			version:      resource.Version{},
+			pullRequests: testPullRequests,
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				resource.NewVersion(testPullRequests[11]),
+			},
+		},
+
+		{
+			description: "check supports regular expression label patterns",
+			source: resource.Source{
+				Repository:  "itsdalmo/test-repository",
+				AccessToken: "oauthtoken",
+				Labels:      []string{"/^enh.+$/"},
+			},
+			version:      resource.Version{},
+			pullRequests: testPullRequests,
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				resource.NewVersion(testPullRequests[6]),
+			},
+		},
+
+		{
+			description: "check requires all labels when labels_match is all",
+			source: resource.Source{
+				Repository:  "itsdalmo/test-repository",
+				AccessToken: "oauthtoken",
+				Labels:      []string{"area/*", "enhancement"},
+				LabelsMatch: "all",
+			},
+			version:      resource.Version{},
+			pullRequests: testPullRequests,
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				resource.NewVersion(testPullRequests[11]),
+			},
+		},
+
+		{
+			description: "check returns a new version dated by the last push when a PR was force pushed",
+			source: resource.Source{
+				Repository:  "itsdalmo/test-repository",
+				AccessToken: "oauthtoken",
+			},
+			version:      resource.NewVersion(testPullRequests[1]),
+			pullRequests: append([]*resource.PullRequest{pushedPullRequest}, testPullRequests...),
+			timelines:    [][]*resource.TimelineEvent{pushedTimeline},
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				pushedVersion,
+			},
+		},
+
+		{
+			description: "check does not return a new version when a PR was updated without a push",
+			source: resource.Source{
+				Repository:  "itsdalmo/test-repository",
+				AccessToken: "oauthtoken",
+			},
+			version:      resource.NewVersion(testPullRequests[1]),
+			pullRequests: append([]*resource.PullRequest{readyPullRequest}, testPullRequests...),
+			timelines:    [][]*resource.TimelineEvent{{{Type: "comment", Created: time.Now()}}},
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				resource.NewVersion(testPullRequests[1]),
+			},
+		},
+
+		{
+			description: "check does not return a new version when the head of the previous version is unchanged",
+			source: resource.Source{
+				Repository:  "itsdalmo/test-repository",
+				AccessToken: "oauthtoken",
+			},
+			version:      pushedVersion,
+			pullRequests: append([]*resource.PullRequest{pushedPullRequest}, testPullRequests...),
+			timelines:    [][]*resource.TimelineEvent{pushedTimeline},
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				pushedVersion,
+			},
+		},
+
+		{
+			description: "check caps the date of commits from the future at the time the PR was updated",
+			source: resource.Source{
+				Repository:  "itsdalmo/test-repository",
+				AccessToken: "oauthtoken",
+			},
+			version:      resource.NewVersion(testPullRequests[1]),
+			pullRequests: []*resource.PullRequest{skewedPullRequest, testPullRequests[1]},
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				skewedVersion,
+			},
+		},
+
+		{
+			description: "check returns PRs pushed after a version with a commit from the future",
+			source: resource.Source{
+				Repository:  "itsdalmo/test-repository",
+				AccessToken: "oauthtoken",
+			},
+			version:      skewedVersion,
+			pullRequests: []*resource.PullRequest{skewedPullRequest, laterPushedPullRequest},
+			files:        [][]string{},
+			timelines: [][]*resource.TimelineEvent{
+				{{Type: resource.TimelineEventPush, Created: laterPushTime}},
+			},
+			expected: resource.CheckResponse{
+				laterPushedVersion,
+			},
+		},
+
+		{
+			description: "check returns a new version when the head of the previous version was force pushed",
+			source: resource.Source{
+				Repository:  "itsdalmo/test-repository",
+				AccessToken: "oauthtoken",
+			},
+			version:      forcePushedPreviousVersion,
+			pullRequests: []*resource.PullRequest{forcePushedPullRequest},
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				forcePushedVersion,
+			},
+		},
+
+		{
+			description: "check returns latest version from a PR with a single state filter",
+			source: resource.Source{
+				Repository:  "itsdalmo/test-repository",
+				AccessToken: "oauthtoken",
+				State:       gitea.StateClosed,
+			},
+			version:      resource.Version{},
+			pullRequests: testPullRequests,
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				resource.NewVersion(testPullRequests[9]),
+			},
+		},
+
+		{
+			description: "check supports the merged state",
+			source: resource.Source{
+				Repository:  "itsdalmo/test-repository",
+				AccessToken: "oauthtoken",
+				States:      []gitea.StateType{resource.StateMerged},
+			},
+			version:      resource.Version{},
+			pullRequests: append([]*resource.PullRequest{unmergedPullRequest}, testPullRequests...),
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				resource.NewVersion(testPullRequests[9]),
+			},
+		},
+
+		{
+			description: "check supports the closed_unmerged state",
+			source: resource.Source{
+				Repository:  "itsdalmo/test-repository",
+				AccessToken: "oauthtoken",
+				States:      []gitea.StateType{resource.StateClosedUnmerged},
+			},
+			version:      resource.Version{},
+			pullRequests: append([]*resource.PullRequest{unmergedPullRequest}, testPullRequests...),
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				resource.NewVersion(unmergedPullRequest),
+			},
+		},
+
+		{
+			description: "check supports a list of states",
+			source: resource.Source{
+				Repository:  "itsdalmo/test-repository",
+				AccessToken: "oauthtoken",
+				States:      []gitea.StateType{gitea.StateOpen, resource.StateClosedUnmerged},
+			},
+			version:      resource.NewVersion(testPullRequests[10]),
+			pullRequests: append([]*resource.PullRequest{unmergedPullRequest}, testPullRequests[8:11]...),
+			files:        [][]string{},
+			expected: resource.CheckResponse{
+				resource.NewVersion(testPullRequests[8]),
+				resource.NewVersion(unmergedPullRequest),
+			},
+		},
+
+		{
+			description: "check filters out versions from a PR which do not match the state filter",
+			source: resource.Source{
+				Repository:  "itsdalmo/test-repository",
+				AccessToken: "oauthtoken",
+				State:       gitea.StateOpen,
+			},
+			version:      resource.Version{},
+			pullRequests: testPullRequests[9:10],
+			files:        [][]string{},
+			expected:     resource.CheckResponse(nil),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			fakeGitea := new(fakes.FakeGitea)
+			fakeGitea.ListPullRequestsStub = func(_ context.Context, filterState gitea.StateType, _ time.Time) ([]*resource.PullRequest, error) {
+				if filterState == gitea.StateAll {
+					return tc.pullRequests, nil
+				}
+				pullRequests := []*resource.PullRequest{}
+				for i := range tc.pullRequests {
+					if filterState == tc.pullRequests[i].PullRequest.State {
+						pullRequests = append(pullRequests, tc.pullRequests[i])
+					}
+				}
+				return pullRequests, nil
+			}
+
+			for i, file := range tc.files {
+				fakeGitea.ListModifiedFilesReturnsOnCall(i, file, nil)
+			}
+
+			fakeGitea.ListTeamMembersReturns(tc.teamMembers, nil)
+
+			fakeGitea.GetCombinedStatusReturns(&gitea.CombinedStatus{}, nil)
+			for i, statuses := range tc.statuses {
+				fakeGitea.GetCombinedStatusReturnsOnCall(i, &gitea.CombinedStatus{Statuses: statuses}, nil)
+			}
+
+			fakeGitea.GetBranchReturns(tc.baseBranch, nil)
+
+			for i, commits := range tc.commits {
+				fakeGitea.ListPullRequestCommitsReturnsOnCall(i, commits, nil)
+			}
+
+			fakeGitea.HasWriteAccessStub = func(_ context.Context, user string) (bool, error) {
+				return user == "maintainer", nil
+			}
+			for i, comments := range tc.comments {
+				fakeGitea.ListPullRequestCommentsReturnsOnCall(i, comments, nil)
+			}
+
+			for i, reviews := range tc.reviews {
+				fakeGitea.ListPullReviewsReturnsOnCall(i, reviews, nil)
+			}
+
+			for i, events := range tc.timelines {
+				fakeGitea.ListPullRequestTimelineReturnsOnCall(i, events, nil)
+			}
+
+			input := resource.CheckRequest{Source: tc.source, Version: tc.version}
+			output, err := resource.Check(context.Background(), input, fakeGitea)
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.expected, output)
+			}
+			assert.Equal(t, 1, fakeGitea.ListPullRequestsCallCount())
+		})
+	}
+}
+
+func TestCheckUpdatedSince(t *testing.T) {
+	committed := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		description string
+		source      resource.Source
+		version     resource.Version
+		expected    time.Time
+	}{
+		{
+			description: "lists all pull requests on the first check",
+			source:      resource.Source{Repository: "itsdalmo/test-repository", AccessToken: "oauthtoken"},
+			expected:    time.Time{},
+		},
+		{
+			description: "lists pull requests updated since the previous version",
+			source:      resource.Source{Repository: "itsdalmo/test-repository", AccessToken: "oauthtoken"},
+			version:     resource.Version{PR: "1", Commit: "oid1", CommittedDate: committed},
+			expected:    committed.Add(-resource.PaginationMargin),
+		},
+		{
+			description: "lists all pull requests when statuses produce versions",
+			source: resource.Source{
+				Repository:             "itsdalmo/test-repository",
+				AccessToken:            "oauthtoken",
+				RequiredStatusContexts: []string{"lint"},
+			},
+			version:  resource.Version{PR: "1", Commit: "oid1", CommittedDate: committed},
+			expected: time.Time{},
+		},
+		{
+			description: "lists all pull requests when base branch changes produce versions",
+			source: resource.Source{
+				Repository:          "itsdalmo/test-repository",
+				AccessToken:         "oauthtoken",
+				RebuildOnBaseChange: true,
+			},
+			version:  resource.Version{PR: "1", Commit: "oid1", CommittedDate: committed},
+			expected: time.Time{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			fakeGitea := new(fakes.FakeGitea)
+
+			input := resource.CheckRequest{Source: tc.source, Version: tc.version}
+			_, err := resource.Check(context.Background(), input, fakeGitea)
+			assert.NoError(t, err)
+
+			if assert.Equal(t, 1, fakeGitea.ListPullRequestsCallCount()) {
+				_, _, updatedSince := fakeGitea.ListPullRequestsArgsForCall(0)
+				assert.Equal(t, tc.expected, updatedSince)
+			}
+		})
+	}
+}
+
+func TestApprovalDate(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		description  string
+		reviews      []*gitea.PullReview
+		required     int
+		officialOnly bool
+		want         time.Time
+		wantApproved bool
+	}{
+		{
+			description: "is not approved without reviews",
+			required:    1,
+		},
+		{
+			description: "returns the time the required approvals were reached",
+			reviews: []*gitea.PullReview{
+				createTestReview("reviewer1", gitea.ReviewStateApproved, false, now.Add(-2*time.Hour)),
+				createTestReview("reviewer2", gitea.ReviewStateApproved, false, now.Add(-1*time.Hour)),
+				createTestReview("reviewer3", gitea.ReviewStateApproved, false, now),
+			},
+			required:     2,
+			want:         now.Add(-1 * time.Hour),
+			wantApproved: true,
+		},
+		{
+			description: "ignores comments, stale and dismissed reviews",
+			reviews: []*gitea.PullReview{
+				createTestReview("reviewer1", gitea.ReviewStateComment, false, now),
+				createTestReview("reviewer2", gitea.ReviewStateApproved, true, now),
+				{
+					Reviewer:  &gitea.User{UserName: "reviewer3"},
+					State:     gitea.ReviewStateApproved,
+					Dismissed: true,
+					Submitted: now,
+				},
+			},
+			required: 1,
+		},
+		{
+			description: "only counts the latest review of each reviewer",
+			reviews: []*gitea.PullReview{
+				createTestReview("reviewer1", gitea.ReviewStateApproved, false, now.Add(-1*time.Hour)),
+				createTestReview("reviewer1", gitea.ReviewStateRequestChanges, false, now),
+				createTestReview("reviewer2", gitea.ReviewStateApproved, false, now),
+			},
+			required: 2,
+		},
+		{
+			description: "only counts official reviews when specified",
+			reviews: []*gitea.PullReview{
+				createTestReview("reviewer1", gitea.ReviewStateApproved, false, now),
+			},
+			required:     1,
+			officialOnly: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			got, approved := resource.ApprovalDate(tc.reviews, tc.required, tc.officialOnly)
+			assert.Equal(t, tc.wantApproved, approved)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func createTestReview(reviewer string, state gitea.ReviewStateType, stale bool, submitted time.Time) *gitea.PullReview {
+	return &gitea.PullReview{
+		Reviewer:  &gitea.User{UserName: reviewer},
+		State:     state,
+		Stale:     stale,
+		Submitted: submitted,
+	}
+}
+
+func TestStatusDate(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		description   string
+		statuses      []*gitea.Status
+		contexts      []string
+		want          time.Time
+		wantSucceeded bool
+	}{
+		{
+			description: "does not succeed without statuses",
+			contexts:    []string{"lint"},
+		},
+		{
+			description: "returns the time the last required context succeeded",
+			statuses: []*gitea.Status{
+				{ID: 1, Context: "lint", State: gitea.StatusSuccess, Updated: now.Add(-1 * time.Hour)},
+				{ID: 2, Context: "ci/unit", State: gitea.StatusSuccess, Updated: now},
+				{ID: 3, Context: "optional", State: gitea.StatusFailure, Updated: now},
+			},
+			contexts:      []string{"lint", "ci/unit"},
+			want:          now,
+			wantSucceeded: true,
+		},
+		{
+			description: "does not succeed if a required context has not succeeded",
+			statuses: []*gitea.Status{
+				{ID: 1, Context: "lint", State: gitea.StatusSuccess, Updated: now},
+				{ID: 2, Context: "ci/unit", State: gitea.StatusPending, Updated: now},
+			},
+			contexts: []string{"lint", "ci/unit"},
+		},
+		{
+			description: "only considers the latest status of each context",
+			statuses: []*gitea.Status{
+				{ID: 2, Context: "lint", State: gitea.StatusFailure, Updated: now},
+				{ID: 1, Context: "lint", State: gitea.StatusSuccess, Updated: now.Add(-1 * time.Hour)},
+			},
+			contexts: []string{"lint"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			got, succeeded := resource.StatusDate(tc.statuses, tc.contexts)
+			assert.Equal(t, tc.wantSucceeded, succeeded)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func createTestStatus(id int64, context string, state gitea.StatusState) *gitea.Status {
+	return &gitea.Status{
+		ID:      id,
+		Context: context,
+		State:   state,
+	}
+}
+
+func TestPushedCommits(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		description    string
+		events         []*resource.TimelineEvent
+		wantPushed     map[string]bool
+		wantForcedFrom string
+	}{
+		{
+			description: "returns the commits pushed since",
+			events: []*resource.TimelineEvent{
+				{Type: resource.TimelineEventPush, Created: now.Add(-2 * time.Hour), Body: `{"is_force_push":false,"commit_ids":["a"]}`},
+				{Type: resource.TimelineEventPush, Created: now, Body: `{"is_force_push":false,"commit_ids":["b","c"]}`},
+				{Type: "comment", Created: now, Body: "b"},
+			},
+			wantPushed: map[string]bool{"b": true, "c": true},
+		},
+		{
+			description: "returns the head before the first force push since",
+			events: []*resource.TimelineEvent{
+				{Type: resource.TimelineEventPush, Created: now.Add(-2 * time.Hour), Body: `{"is_force_push":true,"commit_ids":["a","b"]}`},
+				{Type: resource.TimelineEventPush, Created: now.Add(-30 * time.Minute), Body: `{"is_force_push":true,"commit_ids":["b","c"]}`},
+				{Type: resource.TimelineEventPush, Created: now, Body: `{"is_force_push":true,"commit_ids":["c","d"]}`},
+			},
+			wantPushed:     map[string]bool{},
+			wantForcedFrom: "b",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			pushed, forcedFrom := resource.PushedCommits(tc.events, now.Add(-1*time.Hour))
+			assert.Equal(t, tc.wantPushed, pushed)
+			assert.Equal(t, tc.wantForcedFrom, forcedFrom)
+		})
+	}
+}
+
+func TestReadyDate(t *testing.T) {
+	now := time.Now()
+	prefixes := []string{"WIP:", "[WIP]"}
+
+	tests := []struct {
+		description string
+		events      []*resource.TimelineEvent
+		want        time.Time
+	}{
+		{
+			description: "is zero without title changes",
+			events: []*resource.TimelineEvent{
+				{Type: resource.TimelineEventPush, Created: now},
+			},
+		},
+		{
+			description: "returns the time the WIP prefix was last removed",
+			events: []*resource.TimelineEvent{
+				{Type: resource.TimelineEventChangeTitle, Created: now.Add(-2 * time.Hour), OldTitle: "wip: title", NewTitle: "title"},
+				{Type: resource.TimelineEventChangeTitle, Created: now.Add(-1 * time.Hour), OldTitle: "title", NewTitle: "[WIP] title"},
+				{Type: resource.TimelineEventChangeTitle, Created: now, OldTitle: "[WIP] title", NewTitle: "title"},
+			},
+			want: now,
+		},
+		{
+			description: "ignores other title changes",
+			events: []*resource.TimelineEvent{
+				{Type: resource.TimelineEventChangeTitle, Created: now.Add(-1 * time.Hour), OldTitle: "WIP: title", NewTitle: "title"},
+				{Type: resource.TimelineEventChangeTitle, Created: now, OldTitle: "title", NewTitle: "better title"},
+			},
+			want: now.Add(-1 * time.Hour),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.Equal(t, tc.want, resource.ReadyDate(tc.events, prefixes))
+		})
+	}
+}
+
+func TestContainsSkipCI(t *testing.T) {
+	tests := []struct {
+		description string
+		message     string
+		want        bool
+	}{
+		{
+			description: "does not just match any symbol in the regexp",
+			message:     "(",
+			want:        false,
+		},
+		{
+			description: "does not match when it should not",
+			message:     "test",
+			want:        false,
+		},
+		{
+			description: "matches [ci skip]",
+			message:     "[ci skip]",
+			want:        true,
+		},
+		{
+			description: "matches [skip ci]",
+			message:     "[skip ci]",
+			want:        true,
+		},
+		{
+			description: "matches [no ci]",
+			message:     "[no ci]",
+			want:        true,
+		},
+		{
+			description: "matches trailing skip ci",
+			message:     "trailing [skip ci]",
+			want:        true,
+		},
+		{
+			description: "matches leading skip ci",
+			message:     "[skip ci] leading",
+			want:        true,
+		},
+		{
+			description: "is case insensitive",
+			message:     "case[Skip CI]insensitive",
+			want:        true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			got := resource.ContainsSkipCI(tc.message)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestMatchLabel(t *testing.T) {
+	cases := []struct {
+		description string
+		pattern     string
+		label       string
+		want        bool
+	}{
+		{
+			description: "matches exact labels",
+			pattern:     "bug",
+			label:       "bug",
+			want:        true,
+		},
+		{
+			description: "does not match other labels",
+			pattern:     "bug",
+			label:       "bugfix",
+			want:        false,
+		},
+		{
+			description: "works with wildcard",
+			pattern:     "area/*",
+			label:       "area/ci",
+			want:        true,
+		},
+		{
+			description: "wildcard does not match other prefixes",
+			pattern:     "area/*",
+			label:       "kind/ci",
+			want:        false,
+		},
+		{
+			description: "supports regular expressions enclosed in slashes",
+			pattern:     "/^(kind|area)/.+$/",
+			label:       "kind/bug",
+			want:        true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			got, err := resource.MatchLabel(tc.pattern, tc.label)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestMatchBranch(t *testing.T) {
+	cases := []struct {
+		description string
+		patterns    []string
+		branch      string
+		want        bool
+	}{
+		{
+			description: "matches exact branch names",
+			patterns:    []string{"master"},
+			branch:      "master",
+			want:        true,
+		},
+		{
+			description: "works with wildcard",
+			patterns:    []string{"master", "release/*"},
+			branch:      "release/1.0",
+			want:        true,
+		},
+		{
+			description: "excludes unmatched branches",
+			patterns:    []string{"release/*"},
+			branch:      "develop",
+			want:        false,
+		},
+		{
+			description: "only negated patterns include everything else",
+			patterns:    []string{"!renovate/*"},
+			branch:      "feature/foo",
+			want:        true,
+		},
+		{
+			description: "negated patterns take precedence",
+			patterns:    []string{"*/*", "!renovate/*"},
+			branch:      "renovate/go-1.x",
+			want:        false,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			got, err := resource.MatchBranch(tc.patterns, tc.branch)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.want, got)
+			}
 		})
 	}
 }
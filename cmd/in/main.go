@@ -1,14 +1,21 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 
 	resource "github.com/hur/gitea-pr-resource"
 )
 
 func main() {
+	// Abort pending API requests and git commands when Concourse interrupts the step.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	var request resource.GetRequest
 
 	decoder := json.NewDecoder(os.Stdin)
@@ -25,15 +32,19 @@ func main() {
 	if err := request.Source.Validate(); err != nil {
 		log.Fatalf("invalid source configuration: %s", err)
 	}
+	github, err := resource.NewGiteaClient(ctx, &request.Source)
+	if err != nil {
+		log.Fatalf("failed to create github manager: %s", err)
+	}
 	git, err := resource.NewGitClient(&request.Source, outputDir, os.Stderr)
 	if err != nil {
 		log.Fatalf("failed to create git client: %s", err)
 	}
-	github, err := resource.NewGiteaClient(&request.Source)
-	if err != nil {
-		log.Fatalf("failed to create github manager: %s", err)
+	response, err := resource.Get(ctx, request, github, git, outputDir)
+	// Remove certificates and keys before exiting, as log.Fatalf skips deferred calls.
+	if err := git.Close(); err != nil {
+		log.Printf("failed to clean up git client: %s", err)
 	}
-	response, err := resource.Get(request, github, git, outputDir)
 	if err != nil {
 		log.Fatalf("get failed: %s", err)
 	}
@@ -1,14 +1,21 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 
 	resource "github.com/hur/gitea-pr-resource"
 )
 
 func main() {
+	// Abort pending API requests and git commands when Concourse interrupts the step.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	var request resource.PutRequest
 
 	decoder := json.NewDecoder(os.Stdin)
@@ -25,11 +32,11 @@ func main() {
 	if err := request.Source.Validate(); err != nil {
 		log.Fatalf("invalid source configuration: %s", err)
 	}
-	github, err := resource.NewGiteaClient(&request.Source)
+	github, err := resource.NewGiteaClient(ctx, &request.Source)
 	if err != nil {
 		log.Fatalf("failed to create github manager: %s", err)
 	}
-	response, err := resource.Put(request, github, sourceDir)
+	response, err := resource.Put(ctx, request, github, sourceDir)
 	if err != nil {
 		log.Fatalf("put failed: %s", err)
 	}
@@ -4,6 +4,7 @@
 package e2e_test
 
 import (
+	"context"
 	"io/ioutil"
 	"os"
 	"os/exec"
@@ -136,11 +137,11 @@ func TestCheckE2E(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.description, func(t *testing.T) {
-			giteaClient, err := resource.NewGiteaClient(&tc.source)
+			giteaClient, err := resource.NewGiteaClient(context.Background(), &tc.source)
 			require.NoError(t, err)
 
 			input := resource.CheckRequest{Source: tc.source, Version: tc.version}
-			output, err := resource.Check(input, giteaClient)
+			output, err := resource.Check(context.Background(), input, giteaClient)
 
 			if assert.NoError(t, err) {
 				assert.Equal(t, tc.expected, output)
@@ -178,7 +179,7 @@ func TestGetAndPutE2E(t *testing.T) {
 			getParameters:  resource.GetParameters{},
 			putParameters:  resource.PutParameters{},
 			versionString:  `{"pr":"2","commit":"a5114f6ab89f4b736655642a11e8d15ce363d882","committed":"0001-01-01T00:00:00Z","state":""}`,
-			metadataString: `[{"name":"pr","value":"2"},{"name":"title","value":"Add comment from 2nd pull request."},{"name":"url","value":"` + endpointURL + `atte/e2e-test-repository/pulls/2"},{"name":"head_name","value":"my_second_pull"},{"name":"head_sha","value":"a5114f6ab89f4b736655642a11e8d15ce363d882"},{"name":"base_name","value":"master"},{"name":"base_sha","value":"93eeeedb8a16e6662062d1eca5655108977cc59a"},{"name":"message","value":"Push 2.\n"},{"name":"author","value":"itsdalmo"},{"name":"author_email","value":"kristian@doingit.no"},{"name":"state","value":"open"}]`,
+			metadataString: `[{"name":"pr","value":"2"},{"name":"title","value":"Add comment from 2nd pull request."},{"name":"url","value":"` + endpointURL + `atte/e2e-test-repository/pulls/2"},{"name":"head_name","value":"my_second_pull"},{"name":"head_sha","value":"a5114f6ab89f4b736655642a11e8d15ce363d882"},{"name":"base_name","value":"master"},{"name":"base_sha","value":"93eeeedb8a16e6662062d1eca5655108977cc59a"},{"name":"message","value":"Push 2.\n"},{"name":"author","value":"itsdalmo"},{"name":"author_email","value":"kristian@doingit.no"},{"name":"author_login","value":"itsdalmo"},{"name":"state","value":"open"},{"name":"milestone","value":""},{"name":"assignees","value":""},{"name":"is_fork","value":"false"},{"name":"head_repository","value":"atte/e2e-test-repository"},{"name":"is_draft","value":"false"},{"name":"trust_reason","value":"unrestricted"}]`,
 			metadataFiles: map[string]string{
 				"pr":        "2",
 				"url":       endpointURL + "atte/e2e-test-repository/pulls/2",
@@ -209,7 +210,7 @@ func TestGetAndPutE2E(t *testing.T) {
 			},
 			putParameters:       resource.PutParameters{},
 			versionString:       `{"pr":"2","commit":"a5114f6ab89f4b736655642a11e8d15ce363d882","committed":"0001-01-01T00:00:00Z","state":""}`,
-			metadataString:      `[{"name":"pr","value":"2"},{"name":"title","value":"Add comment from 2nd pull request."},{"name":"url","value":"` + endpointURL + `atte/e2e-test-repository/pulls/2"},{"name":"head_name","value":"my_second_pull"},{"name":"head_sha","value":"a5114f6ab89f4b736655642a11e8d15ce363d882"},{"name":"base_name","value":"master"},{"name":"base_sha","value":"93eeeedb8a16e6662062d1eca5655108977cc59a"},{"name":"message","value":"Push 2.\n"},{"name":"author","value":"itsdalmo"},{"name":"author_email","value":"kristian@doingit.no"},{"name":"state","value":"open"}]`,
+			metadataString:      `[{"name":"pr","value":"2"},{"name":"title","value":"Add comment from 2nd pull request."},{"name":"url","value":"` + endpointURL + `atte/e2e-test-repository/pulls/2"},{"name":"head_name","value":"my_second_pull"},{"name":"head_sha","value":"a5114f6ab89f4b736655642a11e8d15ce363d882"},{"name":"base_name","value":"master"},{"name":"base_sha","value":"93eeeedb8a16e6662062d1eca5655108977cc59a"},{"name":"message","value":"Push 2.\n"},{"name":"author","value":"itsdalmo"},{"name":"author_email","value":"kristian@doingit.no"},{"name":"author_login","value":"itsdalmo"},{"name":"state","value":"open"},{"name":"milestone","value":""},{"name":"assignees","value":""},{"name":"is_fork","value":"false"},{"name":"head_repository","value":"atte/e2e-test-repository"},{"name":"is_draft","value":"false"},{"name":"trust_reason","value":"unrestricted"}]`,
 			expectedCommitCount: 9,
 			expectedCommits:     []string{"Push 2."},
 		},
@@ -230,7 +231,7 @@ func TestGetAndPutE2E(t *testing.T) {
 			},
 			putParameters:       resource.PutParameters{},
 			versionString:       `{"pr":"2","commit":"a5114f6ab89f4b736655642a11e8d15ce363d882","committed":"0001-01-01T00:00:00Z","state":""}`,
-			metadataString:      `[{"name":"pr","value":"2"},{"name":"title","value":"Add comment from 2nd pull request."},{"name":"url","value":"` + endpointURL + `atte/e2e-test-repository/pulls/2"},{"name":"head_name","value":"my_second_pull"},{"name":"head_sha","value":"a5114f6ab89f4b736655642a11e8d15ce363d882"},{"name":"base_name","value":"master"},{"name":"base_sha","value":"93eeeedb8a16e6662062d1eca5655108977cc59a"},{"name":"message","value":"Push 2.\n"},{"name":"author","value":"itsdalmo"},{"name":"author_email","value":"kristian@doingit.no"},{"name":"state","value":"open"}]`,
+			metadataString:      `[{"name":"pr","value":"2"},{"name":"title","value":"Add comment from 2nd pull request."},{"name":"url","value":"` + endpointURL + `atte/e2e-test-repository/pulls/2"},{"name":"head_name","value":"my_second_pull"},{"name":"head_sha","value":"a5114f6ab89f4b736655642a11e8d15ce363d882"},{"name":"base_name","value":"master"},{"name":"base_sha","value":"93eeeedb8a16e6662062d1eca5655108977cc59a"},{"name":"message","value":"Push 2.\n"},{"name":"author","value":"itsdalmo"},{"name":"author_email","value":"kristian@doingit.no"},{"name":"author_login","value":"itsdalmo"},{"name":"state","value":"open"},{"name":"milestone","value":""},{"name":"assignees","value":""},{"name":"is_fork","value":"false"},{"name":"head_repository","value":"atte/e2e-test-repository"},{"name":"is_draft","value":"false"},{"name":"trust_reason","value":"unrestricted"}]`,
 			expectedCommitCount: 7,
 			expectedCommits: []string{
 				"Push 2.",
@@ -257,7 +258,7 @@ func TestGetAndPutE2E(t *testing.T) {
 			getParameters:       resource.GetParameters{},
 			putParameters:       resource.PutParameters{},
 			versionString:       `{"pr":"4","commit":"ac771f3b69cbd63b22bbda553f827ab36150c640","committed":"0001-01-01T00:00:00Z","state":""}`,
-			metadataString:      `[{"name":"pr","value":"4"},{"name":"title","value":"[skip ci] Add a PR with a non-master base"},{"name":"url","value":"` + endpointURL + `atte/e2e-test-repository/pulls/4"},{"name":"head_name","value":"test-develop-pr"},{"name":"head_sha","value":"ac771f3b69cbd63b22bbda553f827ab36150c640"},{"name":"base_name","value":"develop"},{"name":"base_sha","value":"93eeeedb8a16e6662062d1eca5655108977cc59a"},{"name":"message","value":"[skip ci] Add a PR with a non-master base\n"},{"name":"author","value":"itsdalmo"},{"name":"author_email","value":"kristian@doingit.no"},{"name":"state","value":"open"}]`,
+			metadataString:      `[{"name":"pr","value":"4"},{"name":"title","value":"[skip ci] Add a PR with a non-master base"},{"name":"url","value":"` + endpointURL + `atte/e2e-test-repository/pulls/4"},{"name":"head_name","value":"test-develop-pr"},{"name":"head_sha","value":"ac771f3b69cbd63b22bbda553f827ab36150c640"},{"name":"base_name","value":"develop"},{"name":"base_sha","value":"93eeeedb8a16e6662062d1eca5655108977cc59a"},{"name":"message","value":"[skip ci] Add a PR with a non-master base\n"},{"name":"author","value":"itsdalmo"},{"name":"author_email","value":"kristian@doingit.no"},{"name":"author_login","value":"itsdalmo"},{"name":"state","value":"open"},{"name":"milestone","value":""},{"name":"assignees","value":""},{"name":"is_fork","value":"false"},{"name":"head_repository","value":"atte/e2e-test-repository"},{"name":"is_draft","value":"false"},{"name":"trust_reason","value":"unrestricted"}]`,
 			expectedCommitCount: 5,
 			expectedCommits:     []string{"[skip ci] Add a PR with a non-master base"}, // This merge ends up being fast-forwarded
 		},
@@ -276,7 +277,7 @@ func TestGetAndPutE2E(t *testing.T) {
 			getParameters:       resource.GetParameters{GitDepth: 6},
 			putParameters:       resource.PutParameters{},
 			versionString:       `{"pr":"2","commit":"a5114f6ab89f4b736655642a11e8d15ce363d882","committed":"0001-01-01T00:00:00Z","state":""}`,
-			metadataString:      `[{"name":"pr","value":"2"},{"name":"title","value":"Add comment from 2nd pull request."},{"name":"url","value":"` + endpointURL + `atte/e2e-test-repository/pulls/2"},{"name":"head_name","value":"my_second_pull"},{"name":"head_sha","value":"a5114f6ab89f4b736655642a11e8d15ce363d882"},{"name":"base_name","value":"master"},{"name":"base_sha","value":"93eeeedb8a16e6662062d1eca5655108977cc59a"},{"name":"message","value":"Push 2.\n"},{"name":"author","value":"itsdalmo"},{"name":"author_email","value":"kristian@doingit.no"},{"name":"state","value":"open"}]`,
+			metadataString:      `[{"name":"pr","value":"2"},{"name":"title","value":"Add comment from 2nd pull request."},{"name":"url","value":"` + endpointURL + `atte/e2e-test-repository/pulls/2"},{"name":"head_name","value":"my_second_pull"},{"name":"head_sha","value":"a5114f6ab89f4b736655642a11e8d15ce363d882"},{"name":"base_name","value":"master"},{"name":"base_sha","value":"93eeeedb8a16e6662062d1eca5655108977cc59a"},{"name":"message","value":"Push 2.\n"},{"name":"author","value":"itsdalmo"},{"name":"author_email","value":"kristian@doingit.no"},{"name":"author_login","value":"itsdalmo"},{"name":"state","value":"open"},{"name":"milestone","value":""},{"name":"assignees","value":""},{"name":"is_fork","value":"false"},{"name":"head_repository","value":"atte/e2e-test-repository"},{"name":"is_draft","value":"false"},{"name":"trust_reason","value":"unrestricted"}]`,
 			expectedCommitCount: 9,
 			expectedCommits: []string{
 				"Merge commit 'a5114f6ab89f4b736655642a11e8d15ce363d882'",
@@ -299,15 +300,16 @@ func TestGetAndPutE2E(t *testing.T) {
 			require.NoError(t, err)
 			defer os.RemoveAll(dir)
 
-			giteaClient, err := resource.NewGiteaClient(&tc.source)
+			giteaClient, err := resource.NewGiteaClient(context.Background(), &tc.source)
 			require.NoError(t, err)
 
 			git, err := resource.NewGitClient(&tc.source, dir, ioutil.Discard)
 			require.NoError(t, err)
+			defer git.Close()
 
 			// Get (output and files)
 			getRequest := resource.GetRequest{Source: tc.source, Version: tc.version, Params: tc.getParameters}
-			getOutput, err := resource.Get(getRequest, giteaClient, git, dir)
+			getOutput, err := resource.Get(context.Background(), getRequest, giteaClient, git, dir)
 
 			require.NoError(t, err)
 			assert.Equal(t, tc.version, getOutput.Version)
@@ -337,7 +339,7 @@ func TestGetAndPutE2E(t *testing.T) {
 
 			// Put
 			putRequest := resource.PutRequest{Source: tc.source, Params: tc.putParameters}
-			putOutput, err := resource.Put(putRequest, giteaClient, dir)
+			putOutput, err := resource.Put(context.Background(), putRequest, giteaClient, dir)
 
 			require.NoError(t, err)
 			assert.Equal(t, tc.version, putOutput.Version)
@@ -401,15 +403,16 @@ func TestGetSubmodules(t *testing.T) {
 			require.NoError(t, err)
 			defer os.RemoveAll(dir)
 
-			giteaClient, err := resource.NewGiteaClient(&tc.source)
+			giteaClient, err := resource.NewGiteaClient(context.Background(), &tc.source)
 			require.NoError(t, err)
 
 			git, err := resource.NewGitClient(&tc.source, dir, ioutil.Discard)
 			require.NoError(t, err)
+			defer git.Close()
 
 			// Get (output and files)
 			getRequest := resource.GetRequest{Source: tc.source, Version: tc.version, Params: tc.getParameters}
-			_, err = resource.Get(getRequest, giteaClient, git, dir)
+			_, err = resource.Get(context.Background(), getRequest, giteaClient, git, dir)
 			require.NoError(t, err)
 
 			files, err := ioutil.ReadDir(filepath.Join(dir, "submodule"))
@@ -2,18 +2,20 @@
 package fakes
 
 import (
+	"context"
 	"sync"
 
 	resource "github.com/hur/gitea-pr-resource"
 )
 
 type FakeGit struct {
-	CheckoutStub        func(string, string, bool) error
+	CheckoutStub        func(context.Context, string, string, bool) error
 	checkoutMutex       sync.RWMutex
 	checkoutArgsForCall []struct {
-		arg1 string
+		arg1 context.Context
 		arg2 string
-		arg3 bool
+		arg3 string
+		arg4 bool
 	}
 	checkoutReturns struct {
 		result1 error
@@ -21,13 +23,27 @@ type FakeGit struct {
 	checkoutReturnsOnCall map[int]struct {
 		result1 error
 	}
-	FetchStub        func(string, int, int, bool) error
+	ConflictingFilesStub        func(context.Context) ([]string, error)
+	conflictingFilesMutex       sync.RWMutex
+	conflictingFilesArgsForCall []struct {
+		arg1 context.Context
+	}
+	conflictingFilesReturns struct {
+		result1 []string
+		result2 error
+	}
+	conflictingFilesReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
+	FetchStub        func(context.Context, string, int, int, bool) error
 	fetchMutex       sync.RWMutex
 	fetchArgsForCall []struct {
-		arg1 string
-		arg2 int
+		arg1 context.Context
+		arg2 string
 		arg3 int
-		arg4 bool
+		arg4 int
+		arg5 bool
 	}
 	fetchReturns struct {
 		result1 error
@@ -35,10 +51,25 @@ type FakeGit struct {
 	fetchReturnsOnCall map[int]struct {
 		result1 error
 	}
-	InitStub        func(string) error
+	FetchCommitStub        func(context.Context, string, string, int) error
+	fetchCommitMutex       sync.RWMutex
+	fetchCommitArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 int
+	}
+	fetchCommitReturns struct {
+		result1 error
+	}
+	fetchCommitReturnsOnCall map[int]struct {
+		result1 error
+	}
+	InitStub        func(context.Context, string) error
 	initMutex       sync.RWMutex
 	initArgsForCall []struct {
-		arg1 string
+		arg1 context.Context
+		arg2 string
 	}
 	initReturns struct {
 		result1 error
@@ -46,11 +77,12 @@ type FakeGit struct {
 	initReturnsOnCall map[int]struct {
 		result1 error
 	}
-	MergeStub        func(string, bool) error
+	MergeStub        func(context.Context, string, bool) error
 	mergeMutex       sync.RWMutex
 	mergeArgsForCall []struct {
-		arg1 string
-		arg2 bool
+		arg1 context.Context
+		arg2 string
+		arg3 bool
 	}
 	mergeReturns struct {
 		result1 error
@@ -58,14 +90,15 @@ type FakeGit struct {
 	mergeReturnsOnCall map[int]struct {
 		result1 error
 	}
-	PullStub        func(string, string, int, bool, bool) error
+	PullStub        func(context.Context, string, string, int, bool, bool) error
 	pullMutex       sync.RWMutex
 	pullArgsForCall []struct {
-		arg1 string
+		arg1 context.Context
 		arg2 string
-		arg3 int
-		arg4 bool
+		arg3 string
+		arg4 int
 		arg5 bool
+		arg6 bool
 	}
 	pullReturns struct {
 		result1 error
@@ -73,12 +106,13 @@ type FakeGit struct {
 	pullReturnsOnCall map[int]struct {
 		result1 error
 	}
-	RebaseStub        func(string, string, bool) error
+	RebaseStub        func(context.Context, string, string, bool) error
 	rebaseMutex       sync.RWMutex
 	rebaseArgsForCall []struct {
-		arg1 string
+		arg1 context.Context
 		arg2 string
-		arg3 bool
+		arg3 string
+		arg4 bool
 	}
 	rebaseReturns struct {
 		result1 error
@@ -86,10 +120,24 @@ type FakeGit struct {
 	rebaseReturnsOnCall map[int]struct {
 		result1 error
 	}
-	RevParseStub        func(string) (string, error)
+	ResetStub        func(context.Context, string, bool) error
+	resetMutex       sync.RWMutex
+	resetArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 bool
+	}
+	resetReturns struct {
+		result1 error
+	}
+	resetReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RevParseStub        func(context.Context, string) (string, error)
 	revParseMutex       sync.RWMutex
 	revParseArgsForCall []struct {
-		arg1 string
+		arg1 context.Context
+		arg2 string
 	}
 	revParseReturns struct {
 		result1 string
@@ -103,20 +151,21 @@ type FakeGit struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeGit) Checkout(arg1 string, arg2 string, arg3 bool) error {
+func (fake *FakeGit) Checkout(arg1 context.Context, arg2 string, arg3 string, arg4 bool) error {
 	fake.checkoutMutex.Lock()
 	ret, specificReturn := fake.checkoutReturnsOnCall[len(fake.checkoutArgsForCall)]
 	fake.checkoutArgsForCall = append(fake.checkoutArgsForCall, struct {
-		arg1 string
+		arg1 context.Context
 		arg2 string
-		arg3 bool
-	}{arg1, arg2, arg3})
+		arg3 string
+		arg4 bool
+	}{arg1, arg2, arg3, arg4})
 	stub := fake.CheckoutStub
 	fakeReturns := fake.checkoutReturns
-	fake.recordInvocation("Checkout", []interface{}{arg1, arg2, arg3})
+	fake.recordInvocation("Checkout", []interface{}{arg1, arg2, arg3, arg4})
 	fake.checkoutMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3)
+		return stub(arg1, arg2, arg3, arg4)
 	}
 	if specificReturn {
 		return ret.result1
@@ -130,17 +179,17 @@ func (fake *FakeGit) CheckoutCallCount() int {
 	return len(fake.checkoutArgsForCall)
 }
 
-func (fake *FakeGit) CheckoutCalls(stub func(string, string, bool) error) {
+func (fake *FakeGit) CheckoutCalls(stub func(context.Context, string, string, bool) error) {
 	fake.checkoutMutex.Lock()
 	defer fake.checkoutMutex.Unlock()
 	fake.CheckoutStub = stub
 }
 
-func (fake *FakeGit) CheckoutArgsForCall(i int) (string, string, bool) {
+func (fake *FakeGit) CheckoutArgsForCall(i int) (context.Context, string, string, bool) {
 	fake.checkoutMutex.RLock()
 	defer fake.checkoutMutex.RUnlock()
 	argsForCall := fake.checkoutArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
 }
 
 func (fake *FakeGit) CheckoutReturns(result1 error) {
@@ -166,21 +215,86 @@ func (fake *FakeGit) CheckoutReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
-func (fake *FakeGit) Fetch(arg1 string, arg2 int, arg3 int, arg4 bool) error {
+func (fake *FakeGit) ConflictingFiles(arg1 context.Context) ([]string, error) {
+	fake.conflictingFilesMutex.Lock()
+	ret, specificReturn := fake.conflictingFilesReturnsOnCall[len(fake.conflictingFilesArgsForCall)]
+	fake.conflictingFilesArgsForCall = append(fake.conflictingFilesArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.ConflictingFilesStub
+	fakeReturns := fake.conflictingFilesReturns
+	fake.recordInvocation("ConflictingFiles", []interface{}{arg1})
+	fake.conflictingFilesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGit) ConflictingFilesCallCount() int {
+	fake.conflictingFilesMutex.RLock()
+	defer fake.conflictingFilesMutex.RUnlock()
+	return len(fake.conflictingFilesArgsForCall)
+}
+
+func (fake *FakeGit) ConflictingFilesCalls(stub func(context.Context) ([]string, error)) {
+	fake.conflictingFilesMutex.Lock()
+	defer fake.conflictingFilesMutex.Unlock()
+	fake.ConflictingFilesStub = stub
+}
+
+func (fake *FakeGit) ConflictingFilesArgsForCall(i int) context.Context {
+	fake.conflictingFilesMutex.RLock()
+	defer fake.conflictingFilesMutex.RUnlock()
+	argsForCall := fake.conflictingFilesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeGit) ConflictingFilesReturns(result1 []string, result2 error) {
+	fake.conflictingFilesMutex.Lock()
+	defer fake.conflictingFilesMutex.Unlock()
+	fake.ConflictingFilesStub = nil
+	fake.conflictingFilesReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGit) ConflictingFilesReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.conflictingFilesMutex.Lock()
+	defer fake.conflictingFilesMutex.Unlock()
+	fake.ConflictingFilesStub = nil
+	if fake.conflictingFilesReturnsOnCall == nil {
+		fake.conflictingFilesReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.conflictingFilesReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGit) Fetch(arg1 context.Context, arg2 string, arg3 int, arg4 int, arg5 bool) error {
 	fake.fetchMutex.Lock()
 	ret, specificReturn := fake.fetchReturnsOnCall[len(fake.fetchArgsForCall)]
 	fake.fetchArgsForCall = append(fake.fetchArgsForCall, struct {
-		arg1 string
-		arg2 int
+		arg1 context.Context
+		arg2 string
 		arg3 int
-		arg4 bool
-	}{arg1, arg2, arg3, arg4})
+		arg4 int
+		arg5 bool
+	}{arg1, arg2, arg3, arg4, arg5})
 	stub := fake.FetchStub
 	fakeReturns := fake.fetchReturns
-	fake.recordInvocation("Fetch", []interface{}{arg1, arg2, arg3, arg4})
+	fake.recordInvocation("Fetch", []interface{}{arg1, arg2, arg3, arg4, arg5})
 	fake.fetchMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3, arg4)
+		return stub(arg1, arg2, arg3, arg4, arg5)
 	}
 	if specificReturn {
 		return ret.result1
@@ -194,17 +308,17 @@ func (fake *FakeGit) FetchCallCount() int {
 	return len(fake.fetchArgsForCall)
 }
 
-func (fake *FakeGit) FetchCalls(stub func(string, int, int, bool) error) {
+func (fake *FakeGit) FetchCalls(stub func(context.Context, string, int, int, bool) error) {
 	fake.fetchMutex.Lock()
 	defer fake.fetchMutex.Unlock()
 	fake.FetchStub = stub
 }
 
-func (fake *FakeGit) FetchArgsForCall(i int) (string, int, int, bool) {
+func (fake *FakeGit) FetchArgsForCall(i int) (context.Context, string, int, int, bool) {
 	fake.fetchMutex.RLock()
 	defer fake.fetchMutex.RUnlock()
 	argsForCall := fake.fetchArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
 }
 
 func (fake *FakeGit) FetchReturns(result1 error) {
@@ -230,18 +344,83 @@ func (fake *FakeGit) FetchReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
-func (fake *FakeGit) Init(arg1 string) error {
+func (fake *FakeGit) FetchCommit(arg1 context.Context, arg2 string, arg3 string, arg4 int) error {
+	fake.fetchCommitMutex.Lock()
+	ret, specificReturn := fake.fetchCommitReturnsOnCall[len(fake.fetchCommitArgsForCall)]
+	fake.fetchCommitArgsForCall = append(fake.fetchCommitArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 int
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.FetchCommitStub
+	fakeReturns := fake.fetchCommitReturns
+	fake.recordInvocation("FetchCommit", []interface{}{arg1, arg2, arg3, arg4})
+	fake.fetchCommitMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeGit) FetchCommitCallCount() int {
+	fake.fetchCommitMutex.RLock()
+	defer fake.fetchCommitMutex.RUnlock()
+	return len(fake.fetchCommitArgsForCall)
+}
+
+func (fake *FakeGit) FetchCommitCalls(stub func(context.Context, string, string, int) error) {
+	fake.fetchCommitMutex.Lock()
+	defer fake.fetchCommitMutex.Unlock()
+	fake.FetchCommitStub = stub
+}
+
+func (fake *FakeGit) FetchCommitArgsForCall(i int) (context.Context, string, string, int) {
+	fake.fetchCommitMutex.RLock()
+	defer fake.fetchCommitMutex.RUnlock()
+	argsForCall := fake.fetchCommitArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeGit) FetchCommitReturns(result1 error) {
+	fake.fetchCommitMutex.Lock()
+	defer fake.fetchCommitMutex.Unlock()
+	fake.FetchCommitStub = nil
+	fake.fetchCommitReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeGit) FetchCommitReturnsOnCall(i int, result1 error) {
+	fake.fetchCommitMutex.Lock()
+	defer fake.fetchCommitMutex.Unlock()
+	fake.FetchCommitStub = nil
+	if fake.fetchCommitReturnsOnCall == nil {
+		fake.fetchCommitReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.fetchCommitReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeGit) Init(arg1 context.Context, arg2 string) error {
 	fake.initMutex.Lock()
 	ret, specificReturn := fake.initReturnsOnCall[len(fake.initArgsForCall)]
 	fake.initArgsForCall = append(fake.initArgsForCall, struct {
-		arg1 string
-	}{arg1})
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
 	stub := fake.InitStub
 	fakeReturns := fake.initReturns
-	fake.recordInvocation("Init", []interface{}{arg1})
+	fake.recordInvocation("Init", []interface{}{arg1, arg2})
 	fake.initMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
@@ -255,17 +434,17 @@ func (fake *FakeGit) InitCallCount() int {
 	return len(fake.initArgsForCall)
 }
 
-func (fake *FakeGit) InitCalls(stub func(string) error) {
+func (fake *FakeGit) InitCalls(stub func(context.Context, string) error) {
 	fake.initMutex.Lock()
 	defer fake.initMutex.Unlock()
 	fake.InitStub = stub
 }
 
-func (fake *FakeGit) InitArgsForCall(i int) string {
+func (fake *FakeGit) InitArgsForCall(i int) (context.Context, string) {
 	fake.initMutex.RLock()
 	defer fake.initMutex.RUnlock()
 	argsForCall := fake.initArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeGit) InitReturns(result1 error) {
@@ -291,19 +470,20 @@ func (fake *FakeGit) InitReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
-func (fake *FakeGit) Merge(arg1 string, arg2 bool) error {
+func (fake *FakeGit) Merge(arg1 context.Context, arg2 string, arg3 bool) error {
 	fake.mergeMutex.Lock()
 	ret, specificReturn := fake.mergeReturnsOnCall[len(fake.mergeArgsForCall)]
 	fake.mergeArgsForCall = append(fake.mergeArgsForCall, struct {
-		arg1 string
-		arg2 bool
-	}{arg1, arg2})
+		arg1 context.Context
+		arg2 string
+		arg3 bool
+	}{arg1, arg2, arg3})
 	stub := fake.MergeStub
 	fakeReturns := fake.mergeReturns
-	fake.recordInvocation("Merge", []interface{}{arg1, arg2})
+	fake.recordInvocation("Merge", []interface{}{arg1, arg2, arg3})
 	fake.mergeMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2)
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1
@@ -317,17 +497,17 @@ func (fake *FakeGit) MergeCallCount() int {
 	return len(fake.mergeArgsForCall)
 }
 
-func (fake *FakeGit) MergeCalls(stub func(string, bool) error) {
+func (fake *FakeGit) MergeCalls(stub func(context.Context, string, bool) error) {
 	fake.mergeMutex.Lock()
 	defer fake.mergeMutex.Unlock()
 	fake.MergeStub = stub
 }
 
-func (fake *FakeGit) MergeArgsForCall(i int) (string, bool) {
+func (fake *FakeGit) MergeArgsForCall(i int) (context.Context, string, bool) {
 	fake.mergeMutex.RLock()
 	defer fake.mergeMutex.RUnlock()
 	argsForCall := fake.mergeArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
 func (fake *FakeGit) MergeReturns(result1 error) {
@@ -353,22 +533,23 @@ func (fake *FakeGit) MergeReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
-func (fake *FakeGit) Pull(arg1 string, arg2 string, arg3 int, arg4 bool, arg5 bool) error {
+func (fake *FakeGit) Pull(arg1 context.Context, arg2 string, arg3 string, arg4 int, arg5 bool, arg6 bool) error {
 	fake.pullMutex.Lock()
 	ret, specificReturn := fake.pullReturnsOnCall[len(fake.pullArgsForCall)]
 	fake.pullArgsForCall = append(fake.pullArgsForCall, struct {
-		arg1 string
+		arg1 context.Context
 		arg2 string
-		arg3 int
-		arg4 bool
+		arg3 string
+		arg4 int
 		arg5 bool
-	}{arg1, arg2, arg3, arg4, arg5})
+		arg6 bool
+	}{arg1, arg2, arg3, arg4, arg5, arg6})
 	stub := fake.PullStub
 	fakeReturns := fake.pullReturns
-	fake.recordInvocation("Pull", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.recordInvocation("Pull", []interface{}{arg1, arg2, arg3, arg4, arg5, arg6})
 	fake.pullMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3, arg4, arg5)
+		return stub(arg1, arg2, arg3, arg4, arg5, arg6)
 	}
 	if specificReturn {
 		return ret.result1
@@ -382,17 +563,17 @@ func (fake *FakeGit) PullCallCount() int {
 	return len(fake.pullArgsForCall)
 }
 
-func (fake *FakeGit) PullCalls(stub func(string, string, int, bool, bool) error) {
+func (fake *FakeGit) PullCalls(stub func(context.Context, string, string, int, bool, bool) error) {
 	fake.pullMutex.Lock()
 	defer fake.pullMutex.Unlock()
 	fake.PullStub = stub
 }
 
-func (fake *FakeGit) PullArgsForCall(i int) (string, string, int, bool, bool) {
+func (fake *FakeGit) PullArgsForCall(i int) (context.Context, string, string, int, bool, bool) {
 	fake.pullMutex.RLock()
 	defer fake.pullMutex.RUnlock()
 	argsForCall := fake.pullArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5, argsForCall.arg6
 }
 
 func (fake *FakeGit) PullReturns(result1 error) {
@@ -418,20 +599,21 @@ func (fake *FakeGit) PullReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
-func (fake *FakeGit) Rebase(arg1 string, arg2 string, arg3 bool) error {
+func (fake *FakeGit) Rebase(arg1 context.Context, arg2 string, arg3 string, arg4 bool) error {
 	fake.rebaseMutex.Lock()
 	ret, specificReturn := fake.rebaseReturnsOnCall[len(fake.rebaseArgsForCall)]
 	fake.rebaseArgsForCall = append(fake.rebaseArgsForCall, struct {
-		arg1 string
+		arg1 context.Context
 		arg2 string
-		arg3 bool
-	}{arg1, arg2, arg3})
+		arg3 string
+		arg4 bool
+	}{arg1, arg2, arg3, arg4})
 	stub := fake.RebaseStub
 	fakeReturns := fake.rebaseReturns
-	fake.recordInvocation("Rebase", []interface{}{arg1, arg2, arg3})
+	fake.recordInvocation("Rebase", []interface{}{arg1, arg2, arg3, arg4})
 	fake.rebaseMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3)
+		return stub(arg1, arg2, arg3, arg4)
 	}
 	if specificReturn {
 		return ret.result1
@@ -445,17 +627,17 @@ func (fake *FakeGit) RebaseCallCount() int {
 	return len(fake.rebaseArgsForCall)
 }
 
-func (fake *FakeGit) RebaseCalls(stub func(string, string, bool) error) {
+func (fake *FakeGit) RebaseCalls(stub func(context.Context, string, string, bool) error) {
 	fake.rebaseMutex.Lock()
 	defer fake.rebaseMutex.Unlock()
 	fake.RebaseStub = stub
 }
 
-func (fake *FakeGit) RebaseArgsForCall(i int) (string, string, bool) {
+func (fake *FakeGit) RebaseArgsForCall(i int) (context.Context, string, string, bool) {
 	fake.rebaseMutex.RLock()
 	defer fake.rebaseMutex.RUnlock()
 	argsForCall := fake.rebaseArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
 }
 
 func (fake *FakeGit) RebaseReturns(result1 error) {
@@ -481,18 +663,82 @@ func (fake *FakeGit) RebaseReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
-func (fake *FakeGit) RevParse(arg1 string) (string, error) {
+func (fake *FakeGit) Reset(arg1 context.Context, arg2 string, arg3 bool) error {
+	fake.resetMutex.Lock()
+	ret, specificReturn := fake.resetReturnsOnCall[len(fake.resetArgsForCall)]
+	fake.resetArgsForCall = append(fake.resetArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 bool
+	}{arg1, arg2, arg3})
+	stub := fake.ResetStub
+	fakeReturns := fake.resetReturns
+	fake.recordInvocation("Reset", []interface{}{arg1, arg2, arg3})
+	fake.resetMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeGit) ResetCallCount() int {
+	fake.resetMutex.RLock()
+	defer fake.resetMutex.RUnlock()
+	return len(fake.resetArgsForCall)
+}
+
+func (fake *FakeGit) ResetCalls(stub func(context.Context, string, bool) error) {
+	fake.resetMutex.Lock()
+	defer fake.resetMutex.Unlock()
+	fake.ResetStub = stub
+}
+
+func (fake *FakeGit) ResetArgsForCall(i int) (context.Context, string, bool) {
+	fake.resetMutex.RLock()
+	defer fake.resetMutex.RUnlock()
+	argsForCall := fake.resetArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeGit) ResetReturns(result1 error) {
+	fake.resetMutex.Lock()
+	defer fake.resetMutex.Unlock()
+	fake.ResetStub = nil
+	fake.resetReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeGit) ResetReturnsOnCall(i int, result1 error) {
+	fake.resetMutex.Lock()
+	defer fake.resetMutex.Unlock()
+	fake.ResetStub = nil
+	if fake.resetReturnsOnCall == nil {
+		fake.resetReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.resetReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeGit) RevParse(arg1 context.Context, arg2 string) (string, error) {
 	fake.revParseMutex.Lock()
 	ret, specificReturn := fake.revParseReturnsOnCall[len(fake.revParseArgsForCall)]
 	fake.revParseArgsForCall = append(fake.revParseArgsForCall, struct {
-		arg1 string
-	}{arg1})
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
 	stub := fake.RevParseStub
 	fakeReturns := fake.revParseReturns
-	fake.recordInvocation("RevParse", []interface{}{arg1})
+	fake.recordInvocation("RevParse", []interface{}{arg1, arg2})
 	fake.revParseMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -506,17 +752,17 @@ func (fake *FakeGit) RevParseCallCount() int {
 	return len(fake.revParseArgsForCall)
 }
 
-func (fake *FakeGit) RevParseCalls(stub func(string) (string, error)) {
+func (fake *FakeGit) RevParseCalls(stub func(context.Context, string) (string, error)) {
 	fake.revParseMutex.Lock()
 	defer fake.revParseMutex.Unlock()
 	fake.RevParseStub = stub
 }
 
-func (fake *FakeGit) RevParseArgsForCall(i int) string {
+func (fake *FakeGit) RevParseArgsForCall(i int) (context.Context, string) {
 	fake.revParseMutex.RLock()
 	defer fake.revParseMutex.RUnlock()
 	argsForCall := fake.revParseArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeGit) RevParseReturns(result1 string, result2 error) {
@@ -550,8 +796,12 @@ func (fake *FakeGit) Invocations() map[string][][]interface{} {
 	defer fake.invocationsMutex.RUnlock()
 	fake.checkoutMutex.RLock()
 	defer fake.checkoutMutex.RUnlock()
+	fake.conflictingFilesMutex.RLock()
+	defer fake.conflictingFilesMutex.RUnlock()
 	fake.fetchMutex.RLock()
 	defer fake.fetchMutex.RUnlock()
+	fake.fetchCommitMutex.RLock()
+	defer fake.fetchCommitMutex.RUnlock()
 	fake.initMutex.RLock()
 	defer fake.initMutex.RUnlock()
 	fake.mergeMutex.RLock()
@@ -560,6 +810,8 @@ func (fake *FakeGit) Invocations() map[string][][]interface{} {
 	defer fake.pullMutex.RUnlock()
 	fake.rebaseMutex.RLock()
 	defer fake.rebaseMutex.RUnlock()
+	fake.resetMutex.RLock()
+	defer fake.resetMutex.RUnlock()
 	fake.revParseMutex.RLock()
 	defer fake.revParseMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
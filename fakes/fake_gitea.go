@@ -2,18 +2,49 @@
 package fakes
 
 import (
+	"context"
 	"sync"
+	"time"
 
 	"code.gitea.io/sdk/gitea"
 	resource "github.com/hur/gitea-pr-resource"
 )
 
 type FakeGitea struct {
-	GetPullRequestStub        func(string, string) (*resource.PullRequest, error)
+	GetBranchStub        func(context.Context, string) (*gitea.Branch, error)
+	getBranchMutex       sync.RWMutex
+	getBranchArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	getBranchReturns struct {
+		result1 *gitea.Branch
+		result2 error
+	}
+	getBranchReturnsOnCall map[int]struct {
+		result1 *gitea.Branch
+		result2 error
+	}
+	GetCombinedStatusStub        func(context.Context, string) (*gitea.CombinedStatus, error)
+	getCombinedStatusMutex       sync.RWMutex
+	getCombinedStatusArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	getCombinedStatusReturns struct {
+		result1 *gitea.CombinedStatus
+		result2 error
+	}
+	getCombinedStatusReturnsOnCall map[int]struct {
+		result1 *gitea.CombinedStatus
+		result2 error
+	}
+	GetPullRequestStub        func(context.Context, string, string) (*resource.PullRequest, error)
 	getPullRequestMutex       sync.RWMutex
 	getPullRequestArgsForCall []struct {
-		arg1 string
+		arg1 context.Context
 		arg2 string
+		arg3 string
 	}
 	getPullRequestReturns struct {
 		result1 *resource.PullRequest
@@ -23,10 +54,25 @@ type FakeGitea struct {
 		result1 *resource.PullRequest
 		result2 error
 	}
-	ListModifiedFilesStub        func(int64) ([]string, error)
+	HasWriteAccessStub        func(context.Context, string) (bool, error)
+	hasWriteAccessMutex       sync.RWMutex
+	hasWriteAccessArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	hasWriteAccessReturns struct {
+		result1 bool
+		result2 error
+	}
+	hasWriteAccessReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	ListModifiedFilesStub        func(context.Context, int64) ([]string, error)
 	listModifiedFilesMutex       sync.RWMutex
 	listModifiedFilesArgsForCall []struct {
-		arg1 int64
+		arg1 context.Context
+		arg2 int64
 	}
 	listModifiedFilesReturns struct {
 		result1 []string
@@ -36,10 +82,55 @@ type FakeGitea struct {
 		result1 []string
 		result2 error
 	}
-	ListPullRequestsStub        func(gitea.StateType) ([]*resource.PullRequest, error)
+	ListPullRequestCommentsStub        func(context.Context, int64, time.Time) ([]*gitea.Comment, error)
+	listPullRequestCommentsMutex       sync.RWMutex
+	listPullRequestCommentsArgsForCall []struct {
+		arg1 context.Context
+		arg2 int64
+		arg3 time.Time
+	}
+	listPullRequestCommentsReturns struct {
+		result1 []*gitea.Comment
+		result2 error
+	}
+	listPullRequestCommentsReturnsOnCall map[int]struct {
+		result1 []*gitea.Comment
+		result2 error
+	}
+	ListPullRequestCommitsStub        func(context.Context, int64) ([]*gitea.Commit, error)
+	listPullRequestCommitsMutex       sync.RWMutex
+	listPullRequestCommitsArgsForCall []struct {
+		arg1 context.Context
+		arg2 int64
+	}
+	listPullRequestCommitsReturns struct {
+		result1 []*gitea.Commit
+		result2 error
+	}
+	listPullRequestCommitsReturnsOnCall map[int]struct {
+		result1 []*gitea.Commit
+		result2 error
+	}
+	ListPullRequestTimelineStub        func(context.Context, int64) ([]*resource.TimelineEvent, error)
+	listPullRequestTimelineMutex       sync.RWMutex
+	listPullRequestTimelineArgsForCall []struct {
+		arg1 context.Context
+		arg2 int64
+	}
+	listPullRequestTimelineReturns struct {
+		result1 []*resource.TimelineEvent
+		result2 error
+	}
+	listPullRequestTimelineReturnsOnCall map[int]struct {
+		result1 []*resource.TimelineEvent
+		result2 error
+	}
+	ListPullRequestsStub        func(context.Context, gitea.StateType, time.Time) ([]*resource.PullRequest, error)
 	listPullRequestsMutex       sync.RWMutex
 	listPullRequestsArgsForCall []struct {
-		arg1 gitea.StateType
+		arg1 context.Context
+		arg2 gitea.StateType
+		arg3 time.Time
 	}
 	listPullRequestsReturns struct {
 		result1 []*resource.PullRequest
@@ -49,11 +140,41 @@ type FakeGitea struct {
 		result1 []*resource.PullRequest
 		result2 error
 	}
-	PostCommentStub        func(string, string) error
+	ListPullReviewsStub        func(context.Context, int64) ([]*gitea.PullReview, error)
+	listPullReviewsMutex       sync.RWMutex
+	listPullReviewsArgsForCall []struct {
+		arg1 context.Context
+		arg2 int64
+	}
+	listPullReviewsReturns struct {
+		result1 []*gitea.PullReview
+		result2 error
+	}
+	listPullReviewsReturnsOnCall map[int]struct {
+		result1 []*gitea.PullReview
+		result2 error
+	}
+	ListTeamMembersStub        func(context.Context, string, string) ([]string, error)
+	listTeamMembersMutex       sync.RWMutex
+	listTeamMembersArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}
+	listTeamMembersReturns struct {
+		result1 []string
+		result2 error
+	}
+	listTeamMembersReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
+	PostCommentStub        func(context.Context, string, string) error
 	postCommentMutex       sync.RWMutex
 	postCommentArgsForCall []struct {
-		arg1 string
+		arg1 context.Context
 		arg2 string
+		arg3 string
 	}
 	postCommentReturns struct {
 		result1 error
@@ -61,15 +182,16 @@ type FakeGitea struct {
 	postCommentReturnsOnCall map[int]struct {
 		result1 error
 	}
-	UpdateCommitStatusStub        func(string, string, string, string, string, string) error
+	UpdateCommitStatusStub        func(context.Context, string, string, string, string, string, string) error
 	updateCommitStatusMutex       sync.RWMutex
 	updateCommitStatusArgsForCall []struct {
-		arg1 string
+		arg1 context.Context
 		arg2 string
 		arg3 string
 		arg4 string
 		arg5 string
 		arg6 string
+		arg7 string
 	}
 	updateCommitStatusReturns struct {
 		result1 error
@@ -81,19 +203,150 @@ type FakeGitea struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeGitea) GetPullRequest(arg1 string, arg2 string) (*resource.PullRequest, error) {
+func (fake *FakeGitea) GetBranch(arg1 context.Context, arg2 string) (*gitea.Branch, error) {
+	fake.getBranchMutex.Lock()
+	ret, specificReturn := fake.getBranchReturnsOnCall[len(fake.getBranchArgsForCall)]
+	fake.getBranchArgsForCall = append(fake.getBranchArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetBranchStub
+	fakeReturns := fake.getBranchReturns
+	fake.recordInvocation("GetBranch", []interface{}{arg1, arg2})
+	fake.getBranchMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGitea) GetBranchCallCount() int {
+	fake.getBranchMutex.RLock()
+	defer fake.getBranchMutex.RUnlock()
+	return len(fake.getBranchArgsForCall)
+}
+
+func (fake *FakeGitea) GetBranchCalls(stub func(context.Context, string) (*gitea.Branch, error)) {
+	fake.getBranchMutex.Lock()
+	defer fake.getBranchMutex.Unlock()
+	fake.GetBranchStub = stub
+}
+
+func (fake *FakeGitea) GetBranchArgsForCall(i int) (context.Context, string) {
+	fake.getBranchMutex.RLock()
+	defer fake.getBranchMutex.RUnlock()
+	argsForCall := fake.getBranchArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeGitea) GetBranchReturns(result1 *gitea.Branch, result2 error) {
+	fake.getBranchMutex.Lock()
+	defer fake.getBranchMutex.Unlock()
+	fake.GetBranchStub = nil
+	fake.getBranchReturns = struct {
+		result1 *gitea.Branch
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitea) GetBranchReturnsOnCall(i int, result1 *gitea.Branch, result2 error) {
+	fake.getBranchMutex.Lock()
+	defer fake.getBranchMutex.Unlock()
+	fake.GetBranchStub = nil
+	if fake.getBranchReturnsOnCall == nil {
+		fake.getBranchReturnsOnCall = make(map[int]struct {
+			result1 *gitea.Branch
+			result2 error
+		})
+	}
+	fake.getBranchReturnsOnCall[i] = struct {
+		result1 *gitea.Branch
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitea) GetCombinedStatus(arg1 context.Context, arg2 string) (*gitea.CombinedStatus, error) {
+	fake.getCombinedStatusMutex.Lock()
+	ret, specificReturn := fake.getCombinedStatusReturnsOnCall[len(fake.getCombinedStatusArgsForCall)]
+	fake.getCombinedStatusArgsForCall = append(fake.getCombinedStatusArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetCombinedStatusStub
+	fakeReturns := fake.getCombinedStatusReturns
+	fake.recordInvocation("GetCombinedStatus", []interface{}{arg1, arg2})
+	fake.getCombinedStatusMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGitea) GetCombinedStatusCallCount() int {
+	fake.getCombinedStatusMutex.RLock()
+	defer fake.getCombinedStatusMutex.RUnlock()
+	return len(fake.getCombinedStatusArgsForCall)
+}
+
+func (fake *FakeGitea) GetCombinedStatusCalls(stub func(context.Context, string) (*gitea.CombinedStatus, error)) {
+	fake.getCombinedStatusMutex.Lock()
+	defer fake.getCombinedStatusMutex.Unlock()
+	fake.GetCombinedStatusStub = stub
+}
+
+func (fake *FakeGitea) GetCombinedStatusArgsForCall(i int) (context.Context, string) {
+	fake.getCombinedStatusMutex.RLock()
+	defer fake.getCombinedStatusMutex.RUnlock()
+	argsForCall := fake.getCombinedStatusArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeGitea) GetCombinedStatusReturns(result1 *gitea.CombinedStatus, result2 error) {
+	fake.getCombinedStatusMutex.Lock()
+	defer fake.getCombinedStatusMutex.Unlock()
+	fake.GetCombinedStatusStub = nil
+	fake.getCombinedStatusReturns = struct {
+		result1 *gitea.CombinedStatus
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitea) GetCombinedStatusReturnsOnCall(i int, result1 *gitea.CombinedStatus, result2 error) {
+	fake.getCombinedStatusMutex.Lock()
+	defer fake.getCombinedStatusMutex.Unlock()
+	fake.GetCombinedStatusStub = nil
+	if fake.getCombinedStatusReturnsOnCall == nil {
+		fake.getCombinedStatusReturnsOnCall = make(map[int]struct {
+			result1 *gitea.CombinedStatus
+			result2 error
+		})
+	}
+	fake.getCombinedStatusReturnsOnCall[i] = struct {
+		result1 *gitea.CombinedStatus
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitea) GetPullRequest(arg1 context.Context, arg2 string, arg3 string) (*resource.PullRequest, error) {
 	fake.getPullRequestMutex.Lock()
 	ret, specificReturn := fake.getPullRequestReturnsOnCall[len(fake.getPullRequestArgsForCall)]
 	fake.getPullRequestArgsForCall = append(fake.getPullRequestArgsForCall, struct {
-		arg1 string
+		arg1 context.Context
 		arg2 string
-	}{arg1, arg2})
+		arg3 string
+	}{arg1, arg2, arg3})
 	stub := fake.GetPullRequestStub
 	fakeReturns := fake.getPullRequestReturns
-	fake.recordInvocation("GetPullRequest", []interface{}{arg1, arg2})
+	fake.recordInvocation("GetPullRequest", []interface{}{arg1, arg2, arg3})
 	fake.getPullRequestMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2)
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -107,17 +360,17 @@ func (fake *FakeGitea) GetPullRequestCallCount() int {
 	return len(fake.getPullRequestArgsForCall)
 }
 
-func (fake *FakeGitea) GetPullRequestCalls(stub func(string, string) (*resource.PullRequest, error)) {
+func (fake *FakeGitea) GetPullRequestCalls(stub func(context.Context, string, string) (*resource.PullRequest, error)) {
 	fake.getPullRequestMutex.Lock()
 	defer fake.getPullRequestMutex.Unlock()
 	fake.GetPullRequestStub = stub
 }
 
-func (fake *FakeGitea) GetPullRequestArgsForCall(i int) (string, string) {
+func (fake *FakeGitea) GetPullRequestArgsForCall(i int) (context.Context, string, string) {
 	fake.getPullRequestMutex.RLock()
 	defer fake.getPullRequestMutex.RUnlock()
 	argsForCall := fake.getPullRequestArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
 func (fake *FakeGitea) GetPullRequestReturns(result1 *resource.PullRequest, result2 error) {
@@ -146,18 +399,84 @@ func (fake *FakeGitea) GetPullRequestReturnsOnCall(i int, result1 *resource.Pull
 	}{result1, result2}
 }
 
-func (fake *FakeGitea) ListModifiedFiles(arg1 int64) ([]string, error) {
+func (fake *FakeGitea) HasWriteAccess(arg1 context.Context, arg2 string) (bool, error) {
+	fake.hasWriteAccessMutex.Lock()
+	ret, specificReturn := fake.hasWriteAccessReturnsOnCall[len(fake.hasWriteAccessArgsForCall)]
+	fake.hasWriteAccessArgsForCall = append(fake.hasWriteAccessArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.HasWriteAccessStub
+	fakeReturns := fake.hasWriteAccessReturns
+	fake.recordInvocation("HasWriteAccess", []interface{}{arg1, arg2})
+	fake.hasWriteAccessMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGitea) HasWriteAccessCallCount() int {
+	fake.hasWriteAccessMutex.RLock()
+	defer fake.hasWriteAccessMutex.RUnlock()
+	return len(fake.hasWriteAccessArgsForCall)
+}
+
+func (fake *FakeGitea) HasWriteAccessCalls(stub func(context.Context, string) (bool, error)) {
+	fake.hasWriteAccessMutex.Lock()
+	defer fake.hasWriteAccessMutex.Unlock()
+	fake.HasWriteAccessStub = stub
+}
+
+func (fake *FakeGitea) HasWriteAccessArgsForCall(i int) (context.Context, string) {
+	fake.hasWriteAccessMutex.RLock()
+	defer fake.hasWriteAccessMutex.RUnlock()
+	argsForCall := fake.hasWriteAccessArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeGitea) HasWriteAccessReturns(result1 bool, result2 error) {
+	fake.hasWriteAccessMutex.Lock()
+	defer fake.hasWriteAccessMutex.Unlock()
+	fake.HasWriteAccessStub = nil
+	fake.hasWriteAccessReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitea) HasWriteAccessReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.hasWriteAccessMutex.Lock()
+	defer fake.hasWriteAccessMutex.Unlock()
+	fake.HasWriteAccessStub = nil
+	if fake.hasWriteAccessReturnsOnCall == nil {
+		fake.hasWriteAccessReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.hasWriteAccessReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitea) ListModifiedFiles(arg1 context.Context, arg2 int64) ([]string, error) {
 	fake.listModifiedFilesMutex.Lock()
 	ret, specificReturn := fake.listModifiedFilesReturnsOnCall[len(fake.listModifiedFilesArgsForCall)]
 	fake.listModifiedFilesArgsForCall = append(fake.listModifiedFilesArgsForCall, struct {
-		arg1 int64
-	}{arg1})
+		arg1 context.Context
+		arg2 int64
+	}{arg1, arg2})
 	stub := fake.ListModifiedFilesStub
 	fakeReturns := fake.listModifiedFilesReturns
-	fake.recordInvocation("ListModifiedFiles", []interface{}{arg1})
+	fake.recordInvocation("ListModifiedFiles", []interface{}{arg1, arg2})
 	fake.listModifiedFilesMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -171,17 +490,17 @@ func (fake *FakeGitea) ListModifiedFilesCallCount() int {
 	return len(fake.listModifiedFilesArgsForCall)
 }
 
-func (fake *FakeGitea) ListModifiedFilesCalls(stub func(int64) ([]string, error)) {
+func (fake *FakeGitea) ListModifiedFilesCalls(stub func(context.Context, int64) ([]string, error)) {
 	fake.listModifiedFilesMutex.Lock()
 	defer fake.listModifiedFilesMutex.Unlock()
 	fake.ListModifiedFilesStub = stub
 }
 
-func (fake *FakeGitea) ListModifiedFilesArgsForCall(i int) int64 {
+func (fake *FakeGitea) ListModifiedFilesArgsForCall(i int) (context.Context, int64) {
 	fake.listModifiedFilesMutex.RLock()
 	defer fake.listModifiedFilesMutex.RUnlock()
 	argsForCall := fake.listModifiedFilesArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeGitea) ListModifiedFilesReturns(result1 []string, result2 error) {
@@ -210,18 +529,216 @@ func (fake *FakeGitea) ListModifiedFilesReturnsOnCall(i int, result1 []string, r
 	}{result1, result2}
 }
 
-func (fake *FakeGitea) ListPullRequests(arg1 gitea.StateType) ([]*resource.PullRequest, error) {
+func (fake *FakeGitea) ListPullRequestComments(arg1 context.Context, arg2 int64, arg3 time.Time) ([]*gitea.Comment, error) {
+	fake.listPullRequestCommentsMutex.Lock()
+	ret, specificReturn := fake.listPullRequestCommentsReturnsOnCall[len(fake.listPullRequestCommentsArgsForCall)]
+	fake.listPullRequestCommentsArgsForCall = append(fake.listPullRequestCommentsArgsForCall, struct {
+		arg1 context.Context
+		arg2 int64
+		arg3 time.Time
+	}{arg1, arg2, arg3})
+	stub := fake.ListPullRequestCommentsStub
+	fakeReturns := fake.listPullRequestCommentsReturns
+	fake.recordInvocation("ListPullRequestComments", []interface{}{arg1, arg2, arg3})
+	fake.listPullRequestCommentsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGitea) ListPullRequestCommentsCallCount() int {
+	fake.listPullRequestCommentsMutex.RLock()
+	defer fake.listPullRequestCommentsMutex.RUnlock()
+	return len(fake.listPullRequestCommentsArgsForCall)
+}
+
+func (fake *FakeGitea) ListPullRequestCommentsCalls(stub func(context.Context, int64, time.Time) ([]*gitea.Comment, error)) {
+	fake.listPullRequestCommentsMutex.Lock()
+	defer fake.listPullRequestCommentsMutex.Unlock()
+	fake.ListPullRequestCommentsStub = stub
+}
+
+func (fake *FakeGitea) ListPullRequestCommentsArgsForCall(i int) (context.Context, int64, time.Time) {
+	fake.listPullRequestCommentsMutex.RLock()
+	defer fake.listPullRequestCommentsMutex.RUnlock()
+	argsForCall := fake.listPullRequestCommentsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeGitea) ListPullRequestCommentsReturns(result1 []*gitea.Comment, result2 error) {
+	fake.listPullRequestCommentsMutex.Lock()
+	defer fake.listPullRequestCommentsMutex.Unlock()
+	fake.ListPullRequestCommentsStub = nil
+	fake.listPullRequestCommentsReturns = struct {
+		result1 []*gitea.Comment
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitea) ListPullRequestCommentsReturnsOnCall(i int, result1 []*gitea.Comment, result2 error) {
+	fake.listPullRequestCommentsMutex.Lock()
+	defer fake.listPullRequestCommentsMutex.Unlock()
+	fake.ListPullRequestCommentsStub = nil
+	if fake.listPullRequestCommentsReturnsOnCall == nil {
+		fake.listPullRequestCommentsReturnsOnCall = make(map[int]struct {
+			result1 []*gitea.Comment
+			result2 error
+		})
+	}
+	fake.listPullRequestCommentsReturnsOnCall[i] = struct {
+		result1 []*gitea.Comment
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitea) ListPullRequestCommits(arg1 context.Context, arg2 int64) ([]*gitea.Commit, error) {
+	fake.listPullRequestCommitsMutex.Lock()
+	ret, specificReturn := fake.listPullRequestCommitsReturnsOnCall[len(fake.listPullRequestCommitsArgsForCall)]
+	fake.listPullRequestCommitsArgsForCall = append(fake.listPullRequestCommitsArgsForCall, struct {
+		arg1 context.Context
+		arg2 int64
+	}{arg1, arg2})
+	stub := fake.ListPullRequestCommitsStub
+	fakeReturns := fake.listPullRequestCommitsReturns
+	fake.recordInvocation("ListPullRequestCommits", []interface{}{arg1, arg2})
+	fake.listPullRequestCommitsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGitea) ListPullRequestCommitsCallCount() int {
+	fake.listPullRequestCommitsMutex.RLock()
+	defer fake.listPullRequestCommitsMutex.RUnlock()
+	return len(fake.listPullRequestCommitsArgsForCall)
+}
+
+func (fake *FakeGitea) ListPullRequestCommitsCalls(stub func(context.Context, int64) ([]*gitea.Commit, error)) {
+	fake.listPullRequestCommitsMutex.Lock()
+	defer fake.listPullRequestCommitsMutex.Unlock()
+	fake.ListPullRequestCommitsStub = stub
+}
+
+func (fake *FakeGitea) ListPullRequestCommitsArgsForCall(i int) (context.Context, int64) {
+	fake.listPullRequestCommitsMutex.RLock()
+	defer fake.listPullRequestCommitsMutex.RUnlock()
+	argsForCall := fake.listPullRequestCommitsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeGitea) ListPullRequestCommitsReturns(result1 []*gitea.Commit, result2 error) {
+	fake.listPullRequestCommitsMutex.Lock()
+	defer fake.listPullRequestCommitsMutex.Unlock()
+	fake.ListPullRequestCommitsStub = nil
+	fake.listPullRequestCommitsReturns = struct {
+		result1 []*gitea.Commit
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitea) ListPullRequestCommitsReturnsOnCall(i int, result1 []*gitea.Commit, result2 error) {
+	fake.listPullRequestCommitsMutex.Lock()
+	defer fake.listPullRequestCommitsMutex.Unlock()
+	fake.ListPullRequestCommitsStub = nil
+	if fake.listPullRequestCommitsReturnsOnCall == nil {
+		fake.listPullRequestCommitsReturnsOnCall = make(map[int]struct {
+			result1 []*gitea.Commit
+			result2 error
+		})
+	}
+	fake.listPullRequestCommitsReturnsOnCall[i] = struct {
+		result1 []*gitea.Commit
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitea) ListPullRequestTimeline(arg1 context.Context, arg2 int64) ([]*resource.TimelineEvent, error) {
+	fake.listPullRequestTimelineMutex.Lock()
+	ret, specificReturn := fake.listPullRequestTimelineReturnsOnCall[len(fake.listPullRequestTimelineArgsForCall)]
+	fake.listPullRequestTimelineArgsForCall = append(fake.listPullRequestTimelineArgsForCall, struct {
+		arg1 context.Context
+		arg2 int64
+	}{arg1, arg2})
+	stub := fake.ListPullRequestTimelineStub
+	fakeReturns := fake.listPullRequestTimelineReturns
+	fake.recordInvocation("ListPullRequestTimeline", []interface{}{arg1, arg2})
+	fake.listPullRequestTimelineMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGitea) ListPullRequestTimelineCallCount() int {
+	fake.listPullRequestTimelineMutex.RLock()
+	defer fake.listPullRequestTimelineMutex.RUnlock()
+	return len(fake.listPullRequestTimelineArgsForCall)
+}
+
+func (fake *FakeGitea) ListPullRequestTimelineCalls(stub func(context.Context, int64) ([]*resource.TimelineEvent, error)) {
+	fake.listPullRequestTimelineMutex.Lock()
+	defer fake.listPullRequestTimelineMutex.Unlock()
+	fake.ListPullRequestTimelineStub = stub
+}
+
+func (fake *FakeGitea) ListPullRequestTimelineArgsForCall(i int) (context.Context, int64) {
+	fake.listPullRequestTimelineMutex.RLock()
+	defer fake.listPullRequestTimelineMutex.RUnlock()
+	argsForCall := fake.listPullRequestTimelineArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeGitea) ListPullRequestTimelineReturns(result1 []*resource.TimelineEvent, result2 error) {
+	fake.listPullRequestTimelineMutex.Lock()
+	defer fake.listPullRequestTimelineMutex.Unlock()
+	fake.ListPullRequestTimelineStub = nil
+	fake.listPullRequestTimelineReturns = struct {
+		result1 []*resource.TimelineEvent
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitea) ListPullRequestTimelineReturnsOnCall(i int, result1 []*resource.TimelineEvent, result2 error) {
+	fake.listPullRequestTimelineMutex.Lock()
+	defer fake.listPullRequestTimelineMutex.Unlock()
+	fake.ListPullRequestTimelineStub = nil
+	if fake.listPullRequestTimelineReturnsOnCall == nil {
+		fake.listPullRequestTimelineReturnsOnCall = make(map[int]struct {
+			result1 []*resource.TimelineEvent
+			result2 error
+		})
+	}
+	fake.listPullRequestTimelineReturnsOnCall[i] = struct {
+		result1 []*resource.TimelineEvent
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitea) ListPullRequests(arg1 context.Context, arg2 gitea.StateType, arg3 time.Time) ([]*resource.PullRequest, error) {
 	fake.listPullRequestsMutex.Lock()
 	ret, specificReturn := fake.listPullRequestsReturnsOnCall[len(fake.listPullRequestsArgsForCall)]
 	fake.listPullRequestsArgsForCall = append(fake.listPullRequestsArgsForCall, struct {
-		arg1 gitea.StateType
-	}{arg1})
+		arg1 context.Context
+		arg2 gitea.StateType
+		arg3 time.Time
+	}{arg1, arg2, arg3})
 	stub := fake.ListPullRequestsStub
 	fakeReturns := fake.listPullRequestsReturns
-	fake.recordInvocation("ListPullRequests", []interface{}{arg1})
+	fake.recordInvocation("ListPullRequests", []interface{}{arg1, arg2, arg3})
 	fake.listPullRequestsMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -235,17 +752,17 @@ func (fake *FakeGitea) ListPullRequestsCallCount() int {
 	return len(fake.listPullRequestsArgsForCall)
 }
 
-func (fake *FakeGitea) ListPullRequestsCalls(stub func(gitea.StateType) ([]*resource.PullRequest, error)) {
+func (fake *FakeGitea) ListPullRequestsCalls(stub func(context.Context, gitea.StateType, time.Time) ([]*resource.PullRequest, error)) {
 	fake.listPullRequestsMutex.Lock()
 	defer fake.listPullRequestsMutex.Unlock()
 	fake.ListPullRequestsStub = stub
 }
 
-func (fake *FakeGitea) ListPullRequestsArgsForCall(i int) gitea.StateType {
+func (fake *FakeGitea) ListPullRequestsArgsForCall(i int) (context.Context, gitea.StateType, time.Time) {
 	fake.listPullRequestsMutex.RLock()
 	defer fake.listPullRequestsMutex.RUnlock()
 	argsForCall := fake.listPullRequestsArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
 func (fake *FakeGitea) ListPullRequestsReturns(result1 []*resource.PullRequest, result2 error) {
@@ -274,19 +791,151 @@ func (fake *FakeGitea) ListPullRequestsReturnsOnCall(i int, result1 []*resource.
 	}{result1, result2}
 }
 
-func (fake *FakeGitea) PostComment(arg1 string, arg2 string) error {
+func (fake *FakeGitea) ListPullReviews(arg1 context.Context, arg2 int64) ([]*gitea.PullReview, error) {
+	fake.listPullReviewsMutex.Lock()
+	ret, specificReturn := fake.listPullReviewsReturnsOnCall[len(fake.listPullReviewsArgsForCall)]
+	fake.listPullReviewsArgsForCall = append(fake.listPullReviewsArgsForCall, struct {
+		arg1 context.Context
+		arg2 int64
+	}{arg1, arg2})
+	stub := fake.ListPullReviewsStub
+	fakeReturns := fake.listPullReviewsReturns
+	fake.recordInvocation("ListPullReviews", []interface{}{arg1, arg2})
+	fake.listPullReviewsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGitea) ListPullReviewsCallCount() int {
+	fake.listPullReviewsMutex.RLock()
+	defer fake.listPullReviewsMutex.RUnlock()
+	return len(fake.listPullReviewsArgsForCall)
+}
+
+func (fake *FakeGitea) ListPullReviewsCalls(stub func(context.Context, int64) ([]*gitea.PullReview, error)) {
+	fake.listPullReviewsMutex.Lock()
+	defer fake.listPullReviewsMutex.Unlock()
+	fake.ListPullReviewsStub = stub
+}
+
+func (fake *FakeGitea) ListPullReviewsArgsForCall(i int) (context.Context, int64) {
+	fake.listPullReviewsMutex.RLock()
+	defer fake.listPullReviewsMutex.RUnlock()
+	argsForCall := fake.listPullReviewsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeGitea) ListPullReviewsReturns(result1 []*gitea.PullReview, result2 error) {
+	fake.listPullReviewsMutex.Lock()
+	defer fake.listPullReviewsMutex.Unlock()
+	fake.ListPullReviewsStub = nil
+	fake.listPullReviewsReturns = struct {
+		result1 []*gitea.PullReview
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitea) ListPullReviewsReturnsOnCall(i int, result1 []*gitea.PullReview, result2 error) {
+	fake.listPullReviewsMutex.Lock()
+	defer fake.listPullReviewsMutex.Unlock()
+	fake.ListPullReviewsStub = nil
+	if fake.listPullReviewsReturnsOnCall == nil {
+		fake.listPullReviewsReturnsOnCall = make(map[int]struct {
+			result1 []*gitea.PullReview
+			result2 error
+		})
+	}
+	fake.listPullReviewsReturnsOnCall[i] = struct {
+		result1 []*gitea.PullReview
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitea) ListTeamMembers(arg1 context.Context, arg2 string, arg3 string) ([]string, error) {
+	fake.listTeamMembersMutex.Lock()
+	ret, specificReturn := fake.listTeamMembersReturnsOnCall[len(fake.listTeamMembersArgsForCall)]
+	fake.listTeamMembersArgsForCall = append(fake.listTeamMembersArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.ListTeamMembersStub
+	fakeReturns := fake.listTeamMembersReturns
+	fake.recordInvocation("ListTeamMembers", []interface{}{arg1, arg2, arg3})
+	fake.listTeamMembersMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGitea) ListTeamMembersCallCount() int {
+	fake.listTeamMembersMutex.RLock()
+	defer fake.listTeamMembersMutex.RUnlock()
+	return len(fake.listTeamMembersArgsForCall)
+}
+
+func (fake *FakeGitea) ListTeamMembersCalls(stub func(context.Context, string, string) ([]string, error)) {
+	fake.listTeamMembersMutex.Lock()
+	defer fake.listTeamMembersMutex.Unlock()
+	fake.ListTeamMembersStub = stub
+}
+
+func (fake *FakeGitea) ListTeamMembersArgsForCall(i int) (context.Context, string, string) {
+	fake.listTeamMembersMutex.RLock()
+	defer fake.listTeamMembersMutex.RUnlock()
+	argsForCall := fake.listTeamMembersArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeGitea) ListTeamMembersReturns(result1 []string, result2 error) {
+	fake.listTeamMembersMutex.Lock()
+	defer fake.listTeamMembersMutex.Unlock()
+	fake.ListTeamMembersStub = nil
+	fake.listTeamMembersReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitea) ListTeamMembersReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.listTeamMembersMutex.Lock()
+	defer fake.listTeamMembersMutex.Unlock()
+	fake.ListTeamMembersStub = nil
+	if fake.listTeamMembersReturnsOnCall == nil {
+		fake.listTeamMembersReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.listTeamMembersReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitea) PostComment(arg1 context.Context, arg2 string, arg3 string) error {
 	fake.postCommentMutex.Lock()
 	ret, specificReturn := fake.postCommentReturnsOnCall[len(fake.postCommentArgsForCall)]
 	fake.postCommentArgsForCall = append(fake.postCommentArgsForCall, struct {
-		arg1 string
+		arg1 context.Context
 		arg2 string
-	}{arg1, arg2})
+		arg3 string
+	}{arg1, arg2, arg3})
 	stub := fake.PostCommentStub
 	fakeReturns := fake.postCommentReturns
-	fake.recordInvocation("PostComment", []interface{}{arg1, arg2})
+	fake.recordInvocation("PostComment", []interface{}{arg1, arg2, arg3})
 	fake.postCommentMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2)
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1
@@ -300,17 +949,17 @@ func (fake *FakeGitea) PostCommentCallCount() int {
 	return len(fake.postCommentArgsForCall)
 }
 
-func (fake *FakeGitea) PostCommentCalls(stub func(string, string) error) {
+func (fake *FakeGitea) PostCommentCalls(stub func(context.Context, string, string) error) {
 	fake.postCommentMutex.Lock()
 	defer fake.postCommentMutex.Unlock()
 	fake.PostCommentStub = stub
 }
 
-func (fake *FakeGitea) PostCommentArgsForCall(i int) (string, string) {
+func (fake *FakeGitea) PostCommentArgsForCall(i int) (context.Context, string, string) {
 	fake.postCommentMutex.RLock()
 	defer fake.postCommentMutex.RUnlock()
 	argsForCall := fake.postCommentArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
 func (fake *FakeGitea) PostCommentReturns(result1 error) {
@@ -336,23 +985,24 @@ func (fake *FakeGitea) PostCommentReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
-func (fake *FakeGitea) UpdateCommitStatus(arg1 string, arg2 string, arg3 string, arg4 string, arg5 string, arg6 string) error {
+func (fake *FakeGitea) UpdateCommitStatus(arg1 context.Context, arg2 string, arg3 string, arg4 string, arg5 string, arg6 string, arg7 string) error {
 	fake.updateCommitStatusMutex.Lock()
 	ret, specificReturn := fake.updateCommitStatusReturnsOnCall[len(fake.updateCommitStatusArgsForCall)]
 	fake.updateCommitStatusArgsForCall = append(fake.updateCommitStatusArgsForCall, struct {
-		arg1 string
+		arg1 context.Context
 		arg2 string
 		arg3 string
 		arg4 string
 		arg5 string
 		arg6 string
-	}{arg1, arg2, arg3, arg4, arg5, arg6})
+		arg7 string
+	}{arg1, arg2, arg3, arg4, arg5, arg6, arg7})
 	stub := fake.UpdateCommitStatusStub
 	fakeReturns := fake.updateCommitStatusReturns
-	fake.recordInvocation("UpdateCommitStatus", []interface{}{arg1, arg2, arg3, arg4, arg5, arg6})
+	fake.recordInvocation("UpdateCommitStatus", []interface{}{arg1, arg2, arg3, arg4, arg5, arg6, arg7})
 	fake.updateCommitStatusMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3, arg4, arg5, arg6)
+		return stub(arg1, arg2, arg3, arg4, arg5, arg6, arg7)
 	}
 	if specificReturn {
 		return ret.result1
@@ -366,17 +1016,17 @@ func (fake *FakeGitea) UpdateCommitStatusCallCount() int {
 	return len(fake.updateCommitStatusArgsForCall)
 }
 
-func (fake *FakeGitea) UpdateCommitStatusCalls(stub func(string, string, string, string, string, string) error) {
+func (fake *FakeGitea) UpdateCommitStatusCalls(stub func(context.Context, string, string, string, string, string, string) error) {
 	fake.updateCommitStatusMutex.Lock()
 	defer fake.updateCommitStatusMutex.Unlock()
 	fake.UpdateCommitStatusStub = stub
 }
 
-func (fake *FakeGitea) UpdateCommitStatusArgsForCall(i int) (string, string, string, string, string, string) {
+func (fake *FakeGitea) UpdateCommitStatusArgsForCall(i int) (context.Context, string, string, string, string, string, string) {
 	fake.updateCommitStatusMutex.RLock()
 	defer fake.updateCommitStatusMutex.RUnlock()
 	argsForCall := fake.updateCommitStatusArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5, argsForCall.arg6
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5, argsForCall.arg6, argsForCall.arg7
 }
 
 func (fake *FakeGitea) UpdateCommitStatusReturns(result1 error) {
@@ -405,12 +1055,28 @@ func (fake *FakeGitea) UpdateCommitStatusReturnsOnCall(i int, result1 error) {
 func (fake *FakeGitea) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.getBranchMutex.RLock()
+	defer fake.getBranchMutex.RUnlock()
+	fake.getCombinedStatusMutex.RLock()
+	defer fake.getCombinedStatusMutex.RUnlock()
 	fake.getPullRequestMutex.RLock()
 	defer fake.getPullRequestMutex.RUnlock()
+	fake.hasWriteAccessMutex.RLock()
+	defer fake.hasWriteAccessMutex.RUnlock()
 	fake.listModifiedFilesMutex.RLock()
 	defer fake.listModifiedFilesMutex.RUnlock()
+	fake.listPullRequestCommentsMutex.RLock()
+	defer fake.listPullRequestCommentsMutex.RUnlock()
+	fake.listPullRequestCommitsMutex.RLock()
+	defer fake.listPullRequestCommitsMutex.RUnlock()
+	fake.listPullRequestTimelineMutex.RLock()
+	defer fake.listPullRequestTimelineMutex.RUnlock()
 	fake.listPullRequestsMutex.RLock()
 	defer fake.listPullRequestsMutex.RUnlock()
+	fake.listPullReviewsMutex.RLock()
+	defer fake.listPullReviewsMutex.RUnlock()
+	fake.listTeamMembersMutex.RLock()
+	defer fake.listTeamMembersMutex.RUnlock()
 	fake.postCommentMutex.RLock()
 	defer fake.postCommentMutex.RUnlock()
 	fake.updateCommitStatusMutex.RLock()
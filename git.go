@@ -1,95 +1,325 @@
 package resource
 
 import (
+	"context"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Git interface for testing purposes.
 //
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -o fakes/fake_git.go . Git
 type Git interface {
-	Init(string) error
-	Pull(string, string, int, bool, bool) error
-	RevParse(string) (string, error)
-	Fetch(string, int, int, bool) error
-	Checkout(string, string, bool) error
-	Merge(string, bool) error
-	Rebase(string, string, bool) error
+	Init(context.Context, string) error
+	Pull(context.Context, string, string, int, bool, bool) error
+	RevParse(context.Context, string) (string, error)
+	Fetch(context.Context, string, int, int, bool) error
+	FetchCommit(context.Context, string, string, int) error
+	Checkout(context.Context, string, string, bool) error
+	Merge(context.Context, string, bool) error
+	Rebase(context.Context, string, string, bool) error
+	ConflictingFiles(context.Context) ([]string, error)
+	Reset(context.Context, string, bool) error
 }
 
 // NewGitClient ...
 func NewGitClient(source *Source, dir string, output io.Writer) (*GitClient, error) {
-	//if source.SkipSSLVerification {
-	//	os.Setenv("GIT_SSL_NO_VERIFY", "true")
-	//}
 	//if source.DisableGitLFS {
 	//	os.Setenv("GIT_LFS_SKIP_SMUDGE", "true")
 	//}
-	return &GitClient{
-		AccessToken: source.AccessToken,
-		Directory:   dir,
-		Output:      output,
-	}, nil
+	_, timeout := source.Timeouts()
+	username, password := source.GitCredentials()
+
+	// Extra headers are shown by git when tracing, and may contain credentials.
+	secrets := source.Secrets()
+	for _, value := range source.ExtraHeaders {
+		secrets = append(secrets, value)
+	}
+	redactor := NewRedactor(secrets...)
+
+	g := &GitClient{
+		Username:  username,
+		Password:  password,
+		Directory: dir,
+		Output:    redactor.Writer(output),
+		Timeout:   timeout,
+		Config:    make(map[string]string),
+
+		credentialKey: credentialHelperKey(source.Endpoint),
+		redactor:      redactor,
+	}
+
+	// Remove the certificates and keys written so far if the client can not be created.
+	created := false
+	defer func() {
+		if !created {
+			g.Close()
+		}
+	}()
+
+	if source.SkipSSLVerification {
+		g.Config["http.sslVerify"] = "false"
+	}
+	if source.CACert != "" {
+		path, err := g.writeTempFile("ca.pem", source.CACert)
+		if err != nil {
+			return nil, err
+		}
+		g.Config["http.sslCAInfo"] = path
+	}
+	if source.ClientCert != "" {
+		cert, err := g.writeTempFile("client.pem", source.ClientCert)
+		if err != nil {
+			return nil, err
+		}
+		key, err := g.writeTempFile("client.key", source.ClientKey)
+		if err != nil {
+			return nil, err
+		}
+		g.Config["http.sslCert"] = cert
+		g.Config["http.sslKey"] = key
+	}
+
+	if source.PrivateKey != "" {
+		if err := g.configureSSH(source.PrivateKey, source.KnownHosts, source.Verbose); err != nil {
+			return nil, err
+		}
+	}
+
+	if source.HTTPProxy != "" {
+		g.Env = append(g.Env, "http_proxy="+source.HTTPProxy, "https_proxy="+source.HTTPProxy)
+	}
+	if len(source.NoProxy) > 0 {
+		g.Env = append(g.Env, "no_proxy="+strings.Join(source.NoProxy, ","))
+	}
+	// git can not send headers to the proxy, so these would only reach Gitea.
+	g.Headers = source.OriginHeaders()
+
+	if source.Verbose {
+		g.Env = append(g.Env, "GIT_TRACE=1", "GIT_CURL_VERBOSE=1", "GIT_TRACE_REDACT=1")
+	}
+
+	created = true
+	return g, nil
 }
 
 // GitClient ...
 type GitClient struct {
-	AccessToken string
-	Directory   string
-	Output      io.Writer
+	Username  string
+	Password  string
+	Directory string
+	Output    io.Writer
+	Timeout   time.Duration
+
+	// Config is passed to every git command (using -c), so that it is not
+	// written to the repository handed to tasks.
+	Config map[string]string
+	// Headers are sent with every request to Gitea (using http.extraHeader).
+	Headers map[string]string
+	// Env is added to the environment of every git command.
+	Env []string
+
+	// tempDir holds files referenced by Config, such as certificates.
+	tempDir string
+	// credentialKey configures the credential helper, for the Gitea server only.
+	// No helper is configured while it is empty.
+	credentialKey string
+	// redactor masks secrets in errors.
+	redactor *Redactor
+}
+
+// writeTempFile writes the contents to a file which is only readable by the
+// current user, and returns its path. The file is removed by Close.
+func (g *GitClient) writeTempFile(name, contents string) (string, error) {
+	if g.tempDir == "" {
+		dir, err := os.MkdirTemp("", "gitea-pr-resource")
+		if err != nil {
+			return "", fmt.Errorf("failed to create temporary directory: %s", err)
+		}
+		g.tempDir = dir
+	}
+	path := filepath.Join(g.tempDir, name)
+	if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+		return "", fmt.Errorf("failed to write %s: %s", name, err)
+	}
+	return path, nil
 }
 
-func (g *GitClient) command(name string, arg ...string) *exec.Cmd {
-	cmd := exec.Command(name, arg...)
+// configureSSH makes git use the private key for ssh connections, through a
+// GIT_SSH_COMMAND referencing temporary files. Only the known hosts are accepted.
+func (g *GitClient) configureSSH(privateKey, knownHosts string, verbose bool) error {
+	// ssh rejects keys without a trailing newline.
+	if !strings.HasSuffix(privateKey, "\n") {
+		privateKey += "\n"
+	}
+	key, err := g.writeTempFile("id_ssh", privateKey)
+	if err != nil {
+		return err
+	}
+	hosts, err := g.writeTempFile("known_hosts", knownHosts)
+	if err != nil {
+		return err
+	}
+
+	command := fmt.Sprintf(
+		"ssh -i '%s' -o IdentitiesOnly=yes -o UserKnownHostsFile='%s' -o StrictHostKeyChecking=yes",
+		key, hosts,
+	)
+	if verbose {
+		command += " -v"
+	}
+	g.Env = append(g.Env, "GIT_SSH_COMMAND="+command)
+	return nil
+}
+
+// Close flushes the output and removes the temporary files created for the client.
+func (g *GitClient) Close() error {
+	g.flush()
+	if g.tempDir == "" {
+		return nil
+	}
+	if err := os.RemoveAll(g.tempDir); err != nil {
+		return fmt.Errorf("failed to remove temporary files: %s", err)
+	}
+	return nil
+}
+
+// withTimeout returns a context which is cancelled after the git timeout, if any.
+func (g *GitClient) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if g.Timeout == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, g.Timeout)
+}
+
+func (g *GitClient) command(ctx context.Context, name string, arg ...string) *exec.Cmd {
+	if name == "git" {
+		arg = append(g.configArgs(), arg...)
+	}
+	cmd := exec.CommandContext(ctx, name, arg...)
 	cmd.Dir = g.Directory
 	cmd.Stdout = g.Output
 	cmd.Stderr = g.Output
 	cmd.Env = os.Environ()
 	cmd.Env = append(cmd.Env,
-		"X_OAUTH_BASIC_TOKEN="+g.AccessToken,
-		"GIT_ASKPASS=/usr/local/bin/askpass.sh")
+		"GIT_RESOURCE_USERNAME="+g.Username,
+		"GIT_RESOURCE_PASSWORD="+g.Password,
+		"GIT_TERMINAL_PROMPT=0")
+	cmd.Env = append(cmd.Env, g.Env...)
 	return cmd
 }
 
+// run runs the command and flushes the output which is left, so that it is not
+// attributed to the next command.
+func (g *GitClient) run(cmd *exec.Cmd) error {
+	defer g.flush()
+	return cmd.Run()
+}
+
+// flush writes any incomplete line left in the redacted output.
+func (g *GitClient) flush() {
+	if f, ok := g.Output.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+}
+
+// credentialHelper provides the credentials from the environment of the git command,
+// so that they are neither written to disk nor part of any URL.
+const credentialHelper = `!f() { test "$1" = get && echo "username=$GIT_RESOURCE_USERNAME" && echo "password=$GIT_RESOURCE_PASSWORD"; }; f`
+
+// credentialHelperKey returns the key which configures the credential helper for the
+// host of the URL, or an empty string if the URL has no host.
+func credentialHelperKey(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return fmt.Sprintf("credential.%s://%s.helper", u.Scheme, u.Host)
+}
+
+// scopeCredentials scopes the credential helper to the host of the uri, unless it
+// is already scoped to the Gitea endpoint.
+func (g *GitClient) scopeCredentials(uri string) {
+	if g.credentialKey == "" {
+		g.credentialKey = credentialHelperKey(uri)
+	}
+}
+
+// configArgs returns the -c arguments for the credential helper, Config and Headers.
+func (g *GitClient) configArgs() []string {
+	keys := make([]string, 0, len(g.Config))
+	for key := range g.Config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var args []string
+	if g.Password != "" && g.credentialKey != "" {
+		// Reset any configured helpers, so that only ours provides credentials.
+		args = append(args, "-c", g.credentialKey+"=", "-c", g.credentialKey+"="+credentialHelper)
+	}
+	for _, key := range keys {
+		args = append(args, "-c", key+"="+g.Config[key])
+	}
+
+	names := make([]string, 0, len(g.Headers))
+	for name := range g.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		args = append(args, "-c", "http.extraHeader="+name+": "+g.Headers[name])
+	}
+	return args
+}
+
+// errorf formats an error with all secrets redacted.
+func (g *GitClient) errorf(format string, a ...interface{}) error {
+	return g.redactor.Error(fmt.Errorf(format, a...))
+}
+
 // Init ...
-func (g *GitClient) Init(branch string) error {
-	if err := g.command("git", "init").Run(); err != nil {
-		return fmt.Errorf("init failed: %s", err)
+func (g *GitClient) Init(ctx context.Context, branch string) error {
+	ctx, cancel := g.withTimeout(ctx)
+	defer cancel()
+
+	if err := g.run(g.command(ctx, "git", "init")); err != nil {
+		return g.errorf("init failed: %s", err)
 	}
-	if err := g.command("git", "checkout", "-b", branch).Run(); err != nil {
-		return fmt.Errorf("checkout to '%s' failed: %s", branch, err)
+	if err := g.run(g.command(ctx, "git", "checkout", "-b", branch)); err != nil {
+		return g.errorf("checkout to '%s' failed: %s", branch, err)
 	}
-	if err := g.command("git", "config", "user.name", "concourse-ci").Run(); err != nil {
-		return fmt.Errorf("failed to configure git user: %s", err)
+	if err := g.run(g.command(ctx, "git", "config", "user.name", "concourse-ci")); err != nil {
+		return g.errorf("failed to configure git user: %s", err)
 	}
-	if err := g.command("git", "config", "user.email", "concourse@local").Run(); err != nil {
-		return fmt.Errorf("failed to configure git email: %s", err)
+	if err := g.run(g.command(ctx, "git", "config", "user.email", "concourse@local")); err != nil {
+		return g.errorf("failed to configure git email: %s", err)
 	}
 	//if err := g.command("git", "config", "url.https://x-oauth-basic@git.atte.cloud/.insteadOf", "git@git.atte.cloud:").Run(); err != nil {
-	//	return fmt.Errorf("failed to configure gitea url: %s", err)
+	//	return g.errorf("failed to configure gitea url: %s", err)
 	//}
-	if err := g.command("git", "config", "url.https://.insteadOf", "git://").Run(); err != nil {
-		return fmt.Errorf("failed to configure gitea url: %s", err)
+	if err := g.run(g.command(ctx, "git", "config", "url.https://.insteadOf", "git://")); err != nil {
+		return g.errorf("failed to configure gitea url: %s", err)
 	}
 	return nil
 }
 
 // Pull ...
-func (g *GitClient) Pull(uri, branch string, depth int, submodules bool, fetchTags bool) error {
-	endpoint, err := g.Endpoint(uri)
-	if err != nil {
-		return err
-	}
+func (g *GitClient) Pull(ctx context.Context, uri, branch string, depth int, submodules bool, fetchTags bool) error {
+	g.scopeCredentials(uri)
+	ctx, cancel := g.withTimeout(ctx)
+	defer cancel()
 
-	if err := g.command("git", "remote", "add", "origin", endpoint).Run(); err != nil {
-		return fmt.Errorf("setting 'origin' remote to '%s' failed: %s", endpoint, err)
+	if err := g.run(g.command(ctx, "git", "remote", "add", "origin", uri)); err != nil {
+		return g.errorf("setting 'origin' remote to '%s' failed: %s", uri, err)
 	}
 
 	args := []string{"pull", "origin", branch}
@@ -102,70 +332,97 @@ func (g *GitClient) Pull(uri, branch string, depth int, submodules bool, fetchTa
 	if submodules {
 		args = append(args, "--recurse-submodules")
 	}
-	cmd := g.command("git", args...)
-
-	// Discard output to have zero chance of logging the access token.
-	cmd.Stdout = ioutil.Discard
-	cmd.Stderr = ioutil.Discard
-
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("pull failed: %s", cmd)
+	if err := g.run(g.command(ctx, "git", args...)); err != nil {
+		return g.errorf("pull failed: %s", err)
 	}
 	if submodules {
-		submodulesGet := g.command("git", "submodule", "update", "--init", "--recursive")
-		if err := submodulesGet.Run(); err != nil {
-			return fmt.Errorf("submodule update failed: %s", err)
+		if err := g.run(g.command(ctx, "git", "submodule", "update", "--init", "--recursive")); err != nil {
+			return g.errorf("submodule update failed: %s", err)
 		}
 	}
 	return nil
 }
 
 // RevParse retrieves the SHA of the given branch.
-func (g *GitClient) RevParse(branch string) (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--verify", branch)
+func (g *GitClient) RevParse(ctx context.Context, branch string) (string, error) {
+	ctx, cancel := g.withTimeout(ctx)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--verify", branch)
 	cmd.Dir = g.Directory
 	sha, err := cmd.CombinedOutput()
 	if err != nil {
-		return "", fmt.Errorf("rev-parse '%s' failed: %s: %s", branch, err, string(sha))
+		return "", g.errorf("rev-parse '%s' failed: %s: %s", branch, err, string(sha))
 	}
 	return strings.TrimSpace(string(sha)), nil
 }
 
 // Fetch ...
-func (g *GitClient) Fetch(uri string, prNumber int, depth int, submodules bool) error {
-	endpoint, err := g.Endpoint(uri)
-	if err != nil {
-		return err
-	}
+func (g *GitClient) Fetch(ctx context.Context, uri string, prNumber int, depth int, submodules bool) error {
+	g.scopeCredentials(uri)
+	ctx, cancel := g.withTimeout(ctx)
+	defer cancel()
 
-	args := []string{"fetch", endpoint, fmt.Sprintf("pull/%s/head", strconv.Itoa(prNumber))}
+	args := []string{"fetch", uri, fmt.Sprintf("pull/%s/head", strconv.Itoa(prNumber))}
 	if depth > 0 {
 		args = append(args, "--depth", strconv.Itoa(depth))
 	}
 	if submodules {
 		args = append(args, "--recurse-submodules")
 	}
-	cmd := g.command("git", args...)
+	if err := g.run(g.command(ctx, "git", args...)); err != nil {
+		return g.errorf("fetch failed: %s", err)
+	}
+	return nil
+}
 
-	// Discard output to have zero chance of logging the access token.
-	cmd.Stdout = ioutil.Discard
-	cmd.Stderr = ioutil.Discard
+// FetchCommit fetches a single commit, which may not be part of a shallow pull or no
+// longer be reachable from the branch it was pulled from.
+func (g *GitClient) FetchCommit(ctx context.Context, uri, sha string, depth int) error {
+	g.scopeCredentials(uri)
+	ctx, cancel := g.withTimeout(ctx)
+	defer cancel()
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("fetch failed: %s", err)
+	args := []string{"fetch", uri, sha}
+	if depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(depth))
+	}
+	if err := g.run(g.command(ctx, "git", args...)); err != nil {
+		return g.errorf("fetching commit '%s' failed: %s", sha, err)
 	}
 	return nil
 }
 
+// Reset the current branch to the given commit.
+func (g *GitClient) Reset(ctx context.Context, sha string, submodules bool) error {
+	ctx, cancel := g.withTimeout(ctx)
+	defer cancel()
+
+	if err := g.run(g.command(ctx, "git", "reset", "--hard", sha)); err != nil {
+		return g.errorf("reset to '%s' failed: %s", sha, err)
+	}
+
+	if submodules {
+		if err := g.run(g.command(ctx, "git", "submodule", "update", "--init", "--recursive")); err != nil {
+			return g.errorf("submodule update failed: %s", err)
+		}
+	}
+
+	return nil
+}
+
 // CheckOut
-func (g *GitClient) Checkout(branch, sha string, submodules bool) error {
-	if err := g.command("git", "checkout", "-b", branch, sha).Run(); err != nil {
-		return fmt.Errorf("checkout failed: %s", err)
+func (g *GitClient) Checkout(ctx context.Context, branch, sha string, submodules bool) error {
+	ctx, cancel := g.withTimeout(ctx)
+	defer cancel()
+
+	if err := g.run(g.command(ctx, "git", "checkout", "-b", branch, sha)); err != nil {
+		return g.errorf("checkout failed: %s", err)
 	}
 
 	if submodules {
-		if err := g.command("git", "submodule", "update", "--init", "--recursive", "--checkout").Run(); err != nil {
-			return fmt.Errorf("submodule update failed: %s", err)
+		if err := g.run(g.command(ctx, "git", "submodule", "update", "--init", "--recursive", "--checkout")); err != nil {
+			return g.errorf("submodule update failed: %s", err)
 		}
 	}
 
@@ -173,14 +430,17 @@ func (g *GitClient) Checkout(branch, sha string, submodules bool) error {
 }
 
 // Merge ...
-func (g *GitClient) Merge(sha string, submodules bool) error {
-	if err := g.command("git", "merge", sha, "--no-stat").Run(); err != nil {
-		return fmt.Errorf("merge failed: %s", err)
+func (g *GitClient) Merge(ctx context.Context, sha string, submodules bool) error {
+	ctx, cancel := g.withTimeout(ctx)
+	defer cancel()
+
+	if err := g.run(g.command(ctx, "git", "merge", sha, "--no-stat")); err != nil {
+		return g.errorf("merge failed: %s", err)
 	}
 
 	if submodules {
-		if err := g.command("git", "submodule", "update", "--init", "--recursive", "--merge").Run(); err != nil {
-			return fmt.Errorf("submodule update failed: %s", err)
+		if err := g.run(g.command(ctx, "git", "submodule", "update", "--init", "--recursive", "--merge")); err != nil {
+			return g.errorf("submodule update failed: %s", err)
 		}
 	}
 
@@ -188,26 +448,40 @@ func (g *GitClient) Merge(sha string, submodules bool) error {
 }
 
 // Rebase ...
-func (g *GitClient) Rebase(baseRef string, headSha string, submodules bool) error {
-	if err := g.command("git", "rebase", baseRef, headSha).Run(); err != nil {
-		return fmt.Errorf("rebase failed: %s", err)
+func (g *GitClient) Rebase(ctx context.Context, baseRef string, headSha string, submodules bool) error {
+	ctx, cancel := g.withTimeout(ctx)
+	defer cancel()
+
+	if err := g.run(g.command(ctx, "git", "rebase", baseRef, headSha)); err != nil {
+		return g.errorf("rebase failed: %s", err)
 	}
 
 	if submodules {
-		if err := g.command("git", "submodule", "update", "--init", "--recursive", "--rebase").Run(); err != nil {
-			return fmt.Errorf("submodule update failed: %s", err)
+		if err := g.run(g.command(ctx, "git", "submodule", "update", "--init", "--recursive", "--rebase")); err != nil {
+			return g.errorf("submodule update failed: %s", err)
 		}
 	}
 
 	return nil
 }
 
-// Endpoint takes an uri and produces an endpoint with the login information baked in.
-func (g *GitClient) Endpoint(uri string) (string, error) {
-	endpoint, err := url.Parse(uri)
+// ConflictingFiles lists the unmerged paths left behind by a failed merge or rebase.
+func (g *GitClient) ConflictingFiles(ctx context.Context) ([]string, error) {
+	ctx, cancel := g.withTimeout(ctx)
+	defer cancel()
+
+	// Paths are separated by NUL, since they may contain spaces or newlines.
+	cmd := exec.CommandContext(ctx, "git", "diff", "--name-only", "-z", "--diff-filter=U")
+	cmd.Dir = g.Directory
+	out, err := cmd.Output()
 	if err != nil {
-		return "", fmt.Errorf("failed to parse commit url: %s", err)
+		return nil, g.errorf("listing conflicting files failed: %s", err)
+	}
+	var files []string
+	for _, file := range strings.Split(string(out), "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
 	}
-	endpoint.User = url.UserPassword("x-oauth-basic", g.AccessToken)
-	return endpoint.String(), nil
+	return files, nil
 }
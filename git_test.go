@@ -0,0 +1,291 @@
+package resource_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	resource "github.com/hur/gitea-pr-resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGitClientTLS(t *testing.T) {
+	source := resource.Source{
+		Repository:          "owner/repo",
+		AccessToken:         "token",
+		CACert:              "ca certificate",
+		SkipSSLVerification: true,
+		ClientCert:          "client certificate",
+		ClientKey:           "client key",
+	}
+
+	git, err := resource.NewGitClient(&source, t.TempDir(), ioutil.Discard)
+	require.NoError(t, err)
+
+	assert.Equal(t, "false", git.Config["http.sslVerify"])
+	for key, contents := range map[string]string{
+		"http.sslCAInfo": source.CACert,
+		"http.sslCert":   source.ClientCert,
+		"http.sslKey":    source.ClientKey,
+	} {
+		path := git.Config[key]
+		if assert.NotEmpty(t, path, key) {
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+			b, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, contents, string(b))
+		}
+	}
+
+	require.NoError(t, git.Close())
+	for _, key := range []string{"http.sslCAInfo", "http.sslCert", "http.sslKey"} {
+		_, err := os.Stat(git.Config[key])
+		assert.True(t, os.IsNotExist(err), key)
+	}
+}
+
+func TestNewGitClientProxy(t *testing.T) {
+	source := resource.Source{
+		Repository:   "owner/repo",
+		AccessToken:  "token",
+		HTTPProxy:    "http://proxy:3128",
+		NoProxy:      []string{"localhost", ".internal"},
+		ExtraHeaders: map[string]string{"X-Proxy-Token": "secret", "X-Gitea-Token": "other"},
+		ProxyHeaders: []string{"X-Proxy-Token"},
+	}
+
+	git, err := resource.NewGitClient(&source, t.TempDir(), ioutil.Discard)
+	require.NoError(t, err)
+	defer git.Close()
+
+	assert.Equal(t, []string{
+		"http_proxy=http://proxy:3128",
+		"https_proxy=http://proxy:3128",
+		"no_proxy=localhost,.internal",
+	}, git.Env)
+	assert.Equal(t, map[string]string{"X-Gitea-Token": "other"}, git.Headers)
+}
+
+func TestNewGitClientCredentials(t *testing.T) {
+	tests := []struct {
+		description      string
+		source           resource.Source
+		expectedUsername string
+		expectedPassword string
+	}{
+		{
+			description:      "access token",
+			source:           resource.Source{AccessToken: "token"},
+			expectedUsername: "x-oauth-basic",
+			expectedPassword: "token",
+		},
+		{
+			description:      "basic authentication",
+			source:           resource.Source{Username: "user", Password: "password"},
+			expectedUsername: "user",
+			expectedPassword: "password",
+		},
+		{
+			description:      "oauth2 token",
+			source:           resource.Source{OAuthToken: "oauth"},
+			expectedUsername: "oauth2",
+			expectedPassword: "oauth",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			git, err := resource.NewGitClient(&tc.source, t.TempDir(), ioutil.Discard)
+			require.NoError(t, err)
+			defer git.Close()
+
+			assert.Equal(t, tc.expectedUsername, git.Username)
+			assert.Equal(t, tc.expectedPassword, git.Password)
+		})
+	}
+}
+
+func TestNewGitClientSSH(t *testing.T) {
+	source := resource.Source{
+		Repository:    "owner/repo",
+		AccessToken:   "token",
+		CloneProtocol: "ssh",
+		PrivateKey:    "private key",
+		KnownHosts:    "gitea.example.com ssh-ed25519 AAAA",
+	}
+
+	git, err := resource.NewGitClient(&source, t.TempDir(), ioutil.Discard)
+	require.NoError(t, err)
+
+	require.Len(t, git.Env, 1)
+	assert.True(t, strings.HasPrefix(git.Env[0], "GIT_SSH_COMMAND=ssh -i "))
+	assert.Contains(t, git.Env[0], "StrictHostKeyChecking=yes")
+
+	key := strings.Trim(strings.Fields(git.Env[0])[2], "'")
+	b, err := os.ReadFile(key)
+	require.NoError(t, err)
+	assert.Equal(t, "private key\n", string(b))
+
+	require.NoError(t, git.Close())
+	_, err = os.Stat(key)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestGitCredentialHelperScope(t *testing.T) {
+	// The server is called by git, so the header is shared with the test goroutine.
+	var (
+		mu            sync.Mutex
+		authorization string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="gitea"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		authorization = r.Header.Get("Authorization")
+		mu.Unlock()
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		description   string
+		endpoint      string
+		expectedCreds bool
+	}{
+		{
+			description:   "provides credentials to the endpoint",
+			endpoint:      server.URL,
+			expectedCreds: true,
+		},
+		{
+			description:   "provides credentials to the host of the clone url without an endpoint",
+			expectedCreds: true,
+		},
+		{
+			description:   "does not provide credentials to other hosts",
+			endpoint:      "https://gitea.invalid",
+			expectedCreds: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			mu.Lock()
+			authorization = ""
+			mu.Unlock()
+
+			source := resource.Source{
+				Endpoint:   tc.endpoint,
+				Repository: "owner/repo",
+				Username:   "user",
+				Password:   "password",
+			}
+			git, err := resource.NewGitClient(&source, t.TempDir(), ioutil.Discard)
+			require.NoError(t, err)
+			defer git.Close()
+
+			require.NoError(t, git.Init(context.Background(), "master"))
+			err = git.Pull(context.Background(), server.URL+"/owner/repo.git", "master", 0, false, false)
+			assert.Error(t, err)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if tc.expectedCreds {
+				assert.Equal(t, "Basic dXNlcjpwYXNzd29yZA==", authorization)
+			} else {
+				assert.Empty(t, authorization)
+			}
+		})
+	}
+}
+
+func TestConflictingFiles(t *testing.T) {
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	write := func(contents string) {
+		for _, name := range []string{"file with spaces.txt", "README"} {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644))
+		}
+	}
+
+	git("init", "-q")
+	git("config", "user.name", "test")
+	git("config", "user.email", "test@example.com")
+	git("checkout", "-q", "-b", "master")
+	write("base")
+	git("add", ".")
+	git("commit", "-q", "-m", "base")
+	git("checkout", "-q", "-b", "feature")
+	write("feature")
+	git("commit", "-q", "-a", "-m", "feature")
+	git("checkout", "-q", "master")
+	write("master")
+	git("commit", "-q", "-a", "-m", "master")
+
+	client, err := resource.NewGitClient(&resource.Source{Repository: "owner/repo", AccessToken: "token"}, dir, ioutil.Discard)
+	require.NoError(t, err)
+	defer client.Close()
+
+	require.Error(t, client.Merge(context.Background(), "feature", false))
+
+	files, err := client.ConflictingFiles(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"README", "file with spaces.txt"}, files)
+}
+
+func TestFetchCommitAfterShallowPull(t *testing.T) {
+	git := func(dir string, args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+
+	bare := t.TempDir()
+	git(bare, "init", "-q", "--bare")
+	work := t.TempDir()
+	git(work, "init", "-q")
+	git(work, "checkout", "-q", "-b", "master")
+	git(work, "commit", "-q", "--allow-empty", "-m", "pinned")
+	pinned := git(work, "rev-parse", "HEAD")
+	git(work, "commit", "-q", "--allow-empty", "-m", "latest")
+	git(work, "push", "-q", bare, "master")
+
+	dir := t.TempDir()
+	client, err := resource.NewGitClient(&resource.Source{Repository: "owner/repo", AccessToken: "token"}, dir, ioutil.Discard)
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx := context.Background()
+	require.NoError(t, client.Init(ctx, "master"))
+	require.NoError(t, client.Pull(ctx, "file://"+bare, "master", 1, false, false))
+	require.NoError(t, client.FetchCommit(ctx, "file://"+bare, pinned, 1))
+	require.NoError(t, client.Reset(ctx, pinned, false))
+
+	sha, err := client.RevParse(ctx, "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, pinned, sha)
+}
@@ -1,230 +1,449 @@
 package resource
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
 	"path"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"code.gitea.io/sdk/gitea"
 )
 
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -o fakes/fake_gitea.go . Gitea
 type Gitea interface {
-	ListPullRequests(gitea.StateType) ([]*PullRequest, error)
-	ListModifiedFiles(int64) ([]string, error)
-	PostComment(string, string) error
-	GetPullRequest(string, string) (*PullRequest, error)
-	UpdateCommitStatus(string, string, string, string, string, string) error
+	ListPullRequests(context.Context, gitea.StateType, time.Time) ([]*PullRequest, error)
+	ListModifiedFiles(context.Context, int64) ([]string, error)
+	ListPullReviews(context.Context, int64) ([]*gitea.PullReview, error)
+	ListTeamMembers(context.Context, string, string) ([]string, error)
+	GetCombinedStatus(context.Context, string) (*gitea.CombinedStatus, error)
+	ListPullRequestComments(context.Context, int64, time.Time) ([]*gitea.Comment, error)
+	HasWriteAccess(context.Context, string) (bool, error)
+	GetBranch(context.Context, string) (*gitea.Branch, error)
+	ListPullRequestCommits(context.Context, int64) ([]*gitea.Commit, error)
+	ListPullRequestTimeline(context.Context, int64) ([]*TimelineEvent, error)
+	PostComment(context.Context, string, string) error
+	GetPullRequest(context.Context, string, string) (*PullRequest, error)
+	UpdateCommitStatus(context.Context, string, string, string, string, string, string) error
 }
 
 // GiteaClient for handling API requests.
 type GiteaClient struct {
+	// Client is bound to the context of the last call made through the methods of
+	// GiteaClient, which should be used instead where possible.
 	Client     *gitea.Client
 	Repository string
 	Owner      string
+
+	// MaxConcurrency limits the number of concurrent requests used to look up
+	// the tip commit of each pull request.
+	MaxConcurrency int
+	// SkipCommitLookup uses the head SHA and timestamps of the pull request
+	// instead of looking up its tip commit.
+	SkipCommitLookup bool
+
+	// endpoint, httpClient and header are used for requests to API endpoints
+	// which are not supported by the SDK.
+	endpoint   string
+	httpClient *http.Client
+	header     http.Header
 }
 
-func NewGiteaClient(s *Source) (*GiteaClient, error) {
+// DefaultMaxConcurrency is the default number of concurrent tip commit lookups.
+const DefaultMaxConcurrency = 4
+
+// NewGiteaClient creates a client for the repository of the source. The context is
+// only used to check the version of the Gitea server.
+func NewGiteaClient(ctx context.Context, s *Source) (*GiteaClient, error) {
 	owner, repository, err := parseRepository(s.Repository)
 	if err != nil {
 		return nil, err
 	}
 
-	client, err := gitea.NewClient(s.Endpoint, gitea.SetToken(s.AccessToken))
+	httpClient, err := newHTTPClient(s)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []gitea.ClientOption{
+		gitea.SetContext(ctx),
+		gitea.SetHTTPClient(httpClient),
+	}
+	// The header authenticates requests made without the SDK, like the SDK does.
+	header := make(http.Header)
+	switch {
+	case s.Username != "":
+		options = append(options, gitea.SetBasicAuth(s.Username, s.Password))
+		header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(s.Username+":"+s.Password)))
+	case s.AccessToken != "":
+		options = append(options, gitea.SetToken(s.AccessToken))
+		header.Set("Authorization", "token "+s.AccessToken)
+	case s.OAuthToken != "":
+		// Sent as a bearer token by the HTTP client.
+	}
+	if s.Sudo != "" {
+		options = append(options, gitea.SetSudo(s.Sudo))
+		header.Set("Sudo", s.Sudo)
+	}
+
+	client, err := gitea.NewClient(s.Endpoint, options...)
 	if err != nil {
 		return nil, err
 	}
 
+	maxConcurrency := s.MaxConcurrency
+	if maxConcurrency == 0 {
+		maxConcurrency = DefaultMaxConcurrency
+	}
+
 	return &GiteaClient{
-		Client:     client,
-		Repository: repository,
-		Owner:      owner,
+		Client:           client,
+		Repository:       repository,
+		Owner:            owner,
+		MaxConcurrency:   maxConcurrency,
+		SkipCommitLookup: s.SkipCommitLookup,
+
+		endpoint:   strings.TrimSuffix(s.Endpoint, "/"),
+		httpClient: httpClient,
+		header:     header,
 	}, nil
 }
 
-func (manager *GiteaClient) ListPullRequests(prStateFilter gitea.StateType) ([]*PullRequest, error) {
+// client returns the SDK client, with its requests bound to the context. The SDK
+// only supports a single context per client, which is shared by concurrent calls.
+func (manager *GiteaClient) client(ctx context.Context) *gitea.Client {
+	manager.Client.SetContext(ctx)
+	return manager.Client
+}
+
+// ListPullRequests returns the pull requests matching the state filter, most recently
+// updated first. Unless updatedSince is zero, pull requests last updated before it are
+// omitted, and the listing stops at the first page containing such a pull request.
+func (manager *GiteaClient) ListPullRequests(ctx context.Context, prStateFilter gitea.StateType, updatedSince time.Time) ([]*PullRequest, error) {
 	var response []*PullRequest
-	count := 0
-	totalCount := -1
-	page := 1
-	for {
-		prs, httpresponse, err := manager.Client.ListRepoPullRequests(
+	err := paginate(func(options gitea.ListOptions) (int, *gitea.Response, error) {
+		prs, httpResponse, err := manager.client(ctx).ListRepoPullRequests(
 			manager.Owner,
 			manager.Repository,
 			gitea.ListPullRequestsOptions{
-				ListOptions: gitea.ListOptions{
-					Page:     page,
-					PageSize: 100,
-				},
-				State: prStateFilter,
-				Sort:  "recentupdate",
+				ListOptions: options,
+				State:       prStateFilter,
+				Sort:        "recentupdate",
 			},
 		)
-
 		if err != nil {
-			return nil, fmt.Errorf("failed to list pull requests: %s", err)
+			return 0, nil, fmt.Errorf("failed to list pull requests: %s", err)
 		}
-
-		for _, pr := range prs {
-			commit, err := manager.getLatestCommitForPR(pr.Index)
-			if err != nil {
-				return nil, fmt.Errorf("failed to get latest commit for PR: %s", err)
+		count := len(prs)
+
+		exhausted := false
+		if !updatedSince.IsZero() {
+			for i, pr := range prs {
+				if pr.Updated != nil && pr.Updated.Before(updatedSince) {
+					prs = prs[:i]
+					exhausted = true
+					break
+				}
 			}
-			response = append(response, &PullRequest{
-				PullRequest: *pr,
-				Tip:         *commit,
-			})
 		}
 
-		count += len(prs)
-
-		if page == 1 {
-			xTotalCount := httpresponse.Header.Get("x-total-count")
-			if xTotalCount == "" {
-				return nil, errors.New("missing x-total-count header in Gitea API response")
-			}
-
-			totalCount, err = strconv.Atoi(xTotalCount)
-			if err != nil {
-				return nil, errors.New("failed to parse x-total-count header in Gitea API response")
-			}
-
+		enriched, err := manager.enrichPullRequests(ctx, prs)
+		if err != nil {
+			return 0, nil, err
 		}
+		response = append(response, enriched...)
 
-		if count >= totalCount {
-			break
+		if exhausted {
+			return count, httpResponse, errStopPaging
 		}
-
-		page += 1
+		return count, httpResponse, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return response, nil
 }
 
-func (manager *GiteaClient) ListModifiedFiles(prNum int64) ([]string, error) {
+func (manager *GiteaClient) ListModifiedFiles(ctx context.Context, prNum int64) ([]string, error) {
 	var files []string
+	err := paginate(func(options gitea.ListOptions) (int, *gitea.Response, error) {
+		changedFiles, httpResponse, err := manager.client(ctx).ListPullRequestFiles(
+			manager.Owner,
+			manager.Repository,
+			prNum,
+			gitea.ListPullRequestFilesOptions{ListOptions: options},
+		)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to list changed files in pull request: %s", err)
+		}
+		for _, file := range changedFiles {
+			files = append(files, file.Filename)
+		}
+		return len(changedFiles), httpResponse, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
 
-	count := 0
-	totalCount := -1
-	page := 1
-	for {
-		changedFiles, httpresponse, err := manager.Client.ListPullRequestFiles(
+// ListPullReviews returns all reviews submitted to a pull request.
+func (manager *GiteaClient) ListPullReviews(ctx context.Context, prNum int64) ([]*gitea.PullReview, error) {
+	var reviews []*gitea.PullReview
+	err := paginate(func(options gitea.ListOptions) (int, *gitea.Response, error) {
+		pageReviews, httpResponse, err := manager.client(ctx).ListPullReviews(
 			manager.Owner,
 			manager.Repository,
 			prNum,
-			gitea.ListPullRequestFilesOptions{
+			gitea.ListPullReviewsOptions{ListOptions: options},
+		)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to list reviews of pull request: %s", err)
+		}
+		reviews = append(reviews, pageReviews...)
+		return len(pageReviews), httpResponse, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// ListTeamMembers returns the user names of all members of a team in an organization.
+func (manager *GiteaClient) ListTeamMembers(ctx context.Context, org, team string) ([]string, error) {
+	teams, _, err := manager.client(ctx).SearchOrgTeams(org, &gitea.SearchTeamsOptions{Query: team})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search teams: %s", err)
+	}
+
+	var teamID int64
+	for _, t := range teams {
+		if strings.EqualFold(t.Name, team) {
+			teamID = t.ID
+			break
+		}
+	}
+	if teamID == 0 {
+		return nil, fmt.Errorf("team '%s/%s' does not exist", org, team)
+	}
+
+	// The team members endpoint does not return an x-total-count header, so page
+	// through the members until a page comes back short.
+	var members []string
+	page := 1
+	for {
+		users, _, err := manager.client(ctx).ListTeamMembers(
+			teamID,
+			gitea.ListTeamMembersOptions{
 				ListOptions: gitea.ListOptions{
 					Page:     page,
-					PageSize: 100,
+					PageSize: 50,
 				},
 			},
 		)
-
 		if err != nil {
-			return nil, fmt.Errorf("failed to list changed files in pull request: %s", err)
+			return nil, fmt.Errorf("failed to list team members: %s", err)
 		}
 
-		for _, file := range changedFiles {
-			files = append(files, file.Filename)
+		for _, user := range users {
+			members = append(members, user.UserName)
 		}
 
-		count += len(changedFiles)
+		if len(users) < 50 {
+			break
+		}
 
-		if page == 1 {
-			xTotalCount := httpresponse.Header.Get("x-total-count")
-			if xTotalCount == "" {
-				return nil, errors.New("missing x-total-count header in Gitea API response")
-			}
+		page += 1
+	}
 
-			totalCount, err = strconv.Atoi(xTotalCount)
-			if err != nil {
-				return nil, errors.New("failed to parse x-total-count header in Gitea API response")
-			}
+	return members, nil
+}
 
+// ListPullRequestCommits returns all commits in a pull request.
+func (manager *GiteaClient) ListPullRequestCommits(ctx context.Context, prNum int64) ([]*gitea.Commit, error) {
+	var commits []*gitea.Commit
+	err := paginate(func(options gitea.ListOptions) (int, *gitea.Response, error) {
+		pageCommits, httpResponse, err := manager.client(ctx).ListPullRequestCommits(
+			manager.Owner,
+			manager.Repository,
+			prNum,
+			gitea.ListPullRequestCommitsOptions{ListOptions: options},
+		)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to retrieve pull request commits: %s", err)
 		}
+		commits = append(commits, pageCommits...)
+		return len(pageCommits), httpResponse, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return commits, nil
+}
 
-		if count >= totalCount {
-			break
+// ListPullRequestTimeline returns the events in the timeline of a pull request, such
+// as pushes and title changes, oldest first.
+func (manager *GiteaClient) ListPullRequestTimeline(ctx context.Context, prNum int64) ([]*TimelineEvent, error) {
+	var events []*TimelineEvent
+	err := paginate(func(options gitea.ListOptions) (int, *gitea.Response, error) {
+		var pageEvents []*TimelineEvent
+		httpResponse, err := manager.getJSON(ctx, fmt.Sprintf("/repos/%s/%s/issues/%d/timeline?page=%d&limit=%d",
+			url.PathEscape(manager.Owner), url.PathEscape(manager.Repository), prNum, options.Page, options.PageSize), &pageEvents)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to list pull request timeline: %s", err)
 		}
+		events = append(events, pageEvents...)
+		return len(pageEvents), &gitea.Response{Response: httpResponse}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
 
-		page += 1
+// getJSON decodes the response to a GET request for an API endpoint which is not
+// supported by the SDK.
+func (manager *GiteaClient) getJSON(ctx context.Context, path string, v interface{}) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, manager.endpoint+"/api/v1"+path, nil)
+	if err != nil {
+		return nil, err
 	}
+	for name, values := range manager.header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", "application/json")
 
-	return files, nil
+	resp, err := manager.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %s", err)
+	}
+	return resp, nil
 }
 
-func (manager *GiteaClient) GetPullRequest(prNumber, commitRef string) (*PullRequest, error) {
+func (manager *GiteaClient) GetPullRequest(ctx context.Context, prNumber, commitRef string) (*PullRequest, error) {
 	prIndex, err := strconv.ParseInt(prNumber, 10, 64)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert pull request number to int: %s", err)
 	}
 
-	pr, _, err := manager.Client.GetPullRequest(manager.Owner, manager.Repository, prIndex)
+	pr, _, err := manager.client(ctx).GetPullRequest(manager.Owner, manager.Repository, prIndex)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve pull request: %s", err)
 	}
-	count := 0
-	page := 1
-	totalCount := -1
-	for {
-		commits, httpResponse, err := manager.Client.ListPullRequestCommits(
+
+	var tip *gitea.Commit
+	err = paginate(func(options gitea.ListOptions) (int, *gitea.Response, error) {
+		commits, httpResponse, err := manager.client(ctx).ListPullRequestCommits(
 			manager.Owner,
 			manager.Repository,
 			prIndex,
-			gitea.ListPullRequestCommitsOptions{
-				ListOptions: gitea.ListOptions{
-					Page:     page,
-					PageSize: 100,
-				},
-			},
+			gitea.ListPullRequestCommitsOptions{ListOptions: options},
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to retrieve pull request commits: %s", err)
+			return 0, nil, fmt.Errorf("failed to retrieve pull request commits: %s", err)
 		}
-
 		for _, commit := range commits {
 			if commit.SHA == commitRef {
-				return &PullRequest{
-					PullRequest: *pr,
-					Tip:         *commit,
-				}, nil
+				tip = commit
+				return len(commits), httpResponse, errStopPaging
 			}
 		}
+		return len(commits), httpResponse, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Return an error if the commit was not found
+	if tip == nil {
+		return nil, fmt.Errorf("commit with ref '%s' does not exist", commitRef)
+	}
+	return &PullRequest{
+		PullRequest: *pr,
+		Tip:         *tip,
+	}, nil
+}
 
-		count += len(commits)
+// GetCombinedStatus returns the combined commit status of a commit.
+func (manager *GiteaClient) GetCombinedStatus(ctx context.Context, commitRef string) (*gitea.CombinedStatus, error) {
+	status, _, err := manager.client(ctx).GetCombinedStatus(manager.Owner, manager.Repository, commitRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve combined status: %s", err)
+	}
+	return status, nil
+}
 
-		if page == 1 {
-			xTotalCount := httpResponse.Header.Get("x-total-count")
-			if xTotalCount == "" {
-				return nil, errors.New("missing x-total-count header in Gitea API response")
-			}
+// ListPullRequestComments returns the comments on a pull request updated since the
+// given time (or all comments if it is zero). The endpoint is not paginated.
+func (manager *GiteaClient) ListPullRequestComments(ctx context.Context, prNum int64, since time.Time) ([]*gitea.Comment, error) {
+	comments, _, err := manager.client(ctx).ListIssueComments(
+		manager.Owner,
+		manager.Repository,
+		prNum,
+		gitea.ListIssueCommentOptions{
+			Since: since,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull request comments: %s", err)
+	}
+	return comments, nil
+}
 
-			totalCount, err = strconv.Atoi(xTotalCount)
-			if err != nil {
-				return nil, errors.New("failed to parse x-total-count header in Gitea API response")
-			}
-		}
+// HasWriteAccess returns true if the user has (at least) write access to the repository.
+func (manager *GiteaClient) HasWriteAccess(ctx context.Context, user string) (bool, error) {
+	permission, httpResponse, err := manager.client(ctx).CollaboratorPermission(manager.Owner, manager.Repository, user)
+	if httpResponse != nil && httpResponse.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to retrieve permission of %s: %s", user, err)
+	}
+	if permission == nil {
+		return false, nil
+	}
 
-		if count >= totalCount {
-			break
-		}
+	switch permission.Permission {
+	case gitea.AccessModeWrite, gitea.AccessModeAdmin, gitea.AccessModeOwner:
+		return true, nil
+	}
+	return false, nil
+}
 
-		page += 1
+// GetBranch returns a branch of the repository, including its tip commit.
+func (manager *GiteaClient) GetBranch(ctx context.Context, name string) (*gitea.Branch, error) {
+	branch, _, err := manager.client(ctx).GetRepoBranch(manager.Owner, manager.Repository, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve branch '%s': %s", name, err)
 	}
-	// Return an error if the commit was not found
-	return nil, fmt.Errorf("commit with ref '%s' does not exist", commitRef)
+	if branch.Commit == nil {
+		return nil, fmt.Errorf("branch '%s' has no commit", name)
+	}
+	return branch, nil
 }
 
 // PostComment to a pull request or issue.
-func (manager *GiteaClient) PostComment(prNumber, comment string) error {
+func (manager *GiteaClient) PostComment(ctx context.Context, prNumber, comment string) error {
 	prNum, err := strconv.ParseInt(prNumber, 10, 64)
 	if err != nil {
 		return fmt.Errorf("failed to convert pull request number to int: %s", err)
 	}
 
-	_, _, err = manager.Client.CreateIssueComment(
+	_, _, err = manager.client(ctx).CreateIssueComment(
 		manager.Owner,
 		manager.Repository,
 		prNum,
@@ -236,7 +455,7 @@ func (manager *GiteaClient) PostComment(prNumber, comment string) error {
 }
 
 // UpdateCommitStatus for a given commit (not supported by V4 API).
-func (manager *GiteaClient) UpdateCommitStatus(commitRef, baseContext, statusContext, status, targetURL, description string) error {
+func (manager *GiteaClient) UpdateCommitStatus(ctx context.Context, commitRef, baseContext, statusContext, status, targetURL, description string) error {
 	if baseContext == "" {
 		baseContext = "concourse-ci"
 	}
@@ -253,7 +472,7 @@ func (manager *GiteaClient) UpdateCommitStatus(commitRef, baseContext, statusCon
 		description = fmt.Sprintf("Concourse CI build %s", status)
 	}
 
-	_, _, err := manager.Client.CreateStatus(
+	_, _, err := manager.client(ctx).CreateStatus(
 		manager.Owner,
 		manager.Repository,
 		commitRef,
@@ -266,8 +485,114 @@ func (manager *GiteaClient) UpdateCommitStatus(commitRef, baseContext, statusCon
 	return err
 }
 
-func (manager GiteaClient) getLatestCommitForPR(prIndex int64) (*gitea.Commit, error) {
-	commits, _, err := manager.Client.ListPullRequestCommits(
+// enrichPullRequests adds the tip commit to each of the pull requests, using at most
+// MaxConcurrency concurrent requests. The order of the pull requests is preserved,
+// and no further lookups are started once one of them has failed.
+func (manager *GiteaClient) enrichPullRequests(ctx context.Context, prs []*gitea.PullRequest) ([]*PullRequest, error) {
+	response := make([]*PullRequest, len(prs))
+
+	if manager.SkipCommitLookup {
+		for i, pr := range prs {
+			response[i] = pullRequestFromHead(pr)
+		}
+		return response, nil
+	}
+
+	workers := manager.MaxConcurrency
+	if workers < 1 {
+		workers = 1
+	}
+
+	// Cancelling the context aborts the lookups in flight when one of them fails.
+	// The SDK client is shared, so it is bound to the parent context again afterwards,
+	// rather than being left with the cancelled one.
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer manager.Client.SetContext(parent)
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	jobs := make(chan int)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				commit, err := manager.getLatestCommitForPR(ctx, prs[i].Index)
+				if err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					continue
+				}
+				response[i] = &PullRequest{
+					PullRequest: *prs[i],
+					Tip:         *commit,
+				}
+			}
+		}()
+	}
+
+Dispatch:
+	for i := range prs {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break Dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, fmt.Errorf("failed to get latest commit for PR: %s", firstErr)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get latest commit for PR: %s", err)
+	}
+	return response, nil
+}
+
+// pullRequestFromHead creates a pull request with a tip built from the head SHA
+// and the time the pull request was created, without any API requests. Unlike the
+// time it was last updated, this does not change on every comment or label. Later
+// pushes are dated by the timeline during check.
+func pullRequestFromHead(pr *gitea.PullRequest) *PullRequest {
+	var created time.Time
+	if pr.Created != nil {
+		created = *pr.Created
+	}
+
+	var sha string
+	if pr.Head != nil {
+		sha = pr.Head.Sha
+	}
+
+	return &PullRequest{
+		PullRequest: *pr,
+		Tip: gitea.Commit{
+			CommitMeta: &gitea.CommitMeta{
+				SHA:     sha,
+				Created: created,
+			},
+			RepoCommit: &gitea.RepoCommit{
+				Author: &gitea.CommitUser{},
+			},
+		},
+	}
+}
+
+func (manager *GiteaClient) getLatestCommitForPR(ctx context.Context, prIndex int64) (*gitea.Commit, error) {
+	commits, _, err := manager.client(ctx).ListPullRequestCommits(
 		manager.Owner,
 		manager.Repository,
 		prIndex,
@@ -278,10 +603,55 @@ func (manager GiteaClient) getLatestCommitForPR(prIndex int64) (*gitea.Commit, e
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve pull request commits: %s", err)
 	}
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("pull request #%d has no commits", prIndex)
+	}
 
 	return commits[0], nil
 }
 
+// pageSize is the number of items requested per page from paginated endpoints.
+const pageSize = 100
+
+// errStopPaging can be returned by the list function passed to paginate to stop
+// listing further pages.
+var errStopPaging = errors.New("stop paging")
+
+// paginate calls list for each page of a paginated endpoint, until the number of items
+// announced by the x-total-count header of the first page has been listed. The list
+// function returns the number of items on the page and the response it was read from.
+func paginate(list func(gitea.ListOptions) (int, *gitea.Response, error)) error {
+	count := 0
+	totalCount := -1
+	for page := 1; ; page++ {
+		n, httpResponse, err := list(gitea.ListOptions{Page: page, PageSize: pageSize})
+		if err == errStopPaging {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		count += n
+
+		if page == 1 {
+			xTotalCount := httpResponse.Header.Get("x-total-count")
+			if xTotalCount == "" {
+				return errors.New("missing x-total-count header in Gitea API response")
+			}
+
+			totalCount, err = strconv.Atoi(xTotalCount)
+			if err != nil {
+				return errors.New("failed to parse x-total-count header in Gitea API response")
+			}
+		}
+
+		// An empty page ends the listing, should items be removed while paging.
+		if n == 0 || count >= totalCount {
+			return nil
+		}
+	}
+}
+
 func parseRepository(s string) (string, string, error) {
 	parts := strings.Split(s, "/")
 	if len(parts) != 2 {
@@ -289,3 +659,11 @@ func parseRepository(s string) (string, string, error) {
 	}
 	return parts[0], parts[1], nil
 }
+
+func parseTeam(s string) (string, string, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("malformed team string '%s', expected 'organization/team'", s)
+	}
+	return parts[0], parts[1], nil
+}
@@ -1,42 +1,70 @@
 package resource
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/sdk/gitea"
 )
 
-func Get(request GetRequest, gitea Gitea, git Git, outputDir string) (*GetResponse, error) {
+func Get(ctx context.Context, request GetRequest, gitea Gitea, git Git, outputDir string) (*GetResponse, error) {
 	if request.Params.SkipDownload {
 		return &GetResponse{Version: request.Version}, nil
 	}
-	pr, err := gitea.GetPullRequest(request.Version.PR, request.Version.Commit)
+	pr, err := gitea.GetPullRequest(ctx, request.Version.PR, request.Version.Commit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve pull request: %s", err)
 	}
 	// Initialize and pull the base for the PR
-	if err := git.Init(pr.Base.Ref); err != nil {
+	if err := git.Init(ctx, pr.Base.Ref); err != nil {
 		return nil, err
 	}
-	if err := git.Pull(pr.Base.Repository.CloneURL, pr.Base.Ref, request.Params.GitDepth, request.Params.Submodules, request.Params.FetchTags); err != nil {
+	if err := git.Pull(ctx, request.Source.CloneURL(pr.Base.Repository), pr.Base.Ref, request.Params.GitDepth, request.Params.Submodules, request.Params.FetchTags); err != nil {
 		return nil, err
 	}
 
+	// Pin the base to the commit in the version, if any. The commit is fetched first,
+	// since a shallow pull may not contain it.
+	if request.Version.BaseCommit != "" {
+		if err := git.FetchCommit(ctx, request.Source.CloneURL(pr.Base.Repository), request.Version.BaseCommit, request.Params.GitDepth); err != nil {
+			return nil, err
+		}
+		if err := git.Reset(ctx, request.Version.BaseCommit, request.Params.Submodules); err != nil {
+			return nil, err
+		}
+	}
+
 	// Get the last commit SHA in base for the metadata
-	baseSHA, err := git.RevParse(pr.Base.Ref)
+	baseSHA, err := git.RevParse(ctx, pr.Base.Ref)
 	if err != nil {
 		return nil, err
 	}
 
-	// Fetch the PR and merge the specified commit into the base
-	//fmt.Printf("%v %v %v %v", pr.Head.Repository.CloneURL, int(pr.Index), request.Params.GitDepth, request.Params.Submodules)
-	if err := git.Fetch(pr.Head.Repository.CloneURL, int(pr.Index), request.Params.GitDepth, request.Params.Submodules); err != nil {
+	// Fetch the PR and merge the specified commit into the base. Gitea keeps the head of
+	// every PR in the base repository, which also works when the fork has been deleted.
+	if err := git.Fetch(ctx, request.Source.CloneURL(pr.Base.Repository), int(pr.Index), request.Params.GitDepth, request.Params.Submodules); err != nil {
 		return nil, err
 	}
 
+	// Trust is decided by check, which records the reason in the version unless the
+	// source is unrestricted. Versions from before are judged without the trusted teams,
+	// so that get never depends on the team API.
+	trust := request.Version.TrustReason
+	if trust == "" {
+		trust = trustReason(request.Source, pr, trustedUsers(request.Source))
+	}
+	if trust == "" {
+		trust = "untrusted"
+	}
+
 	// Create the metadata
 	var metadata Metadata
 	metadata.Add("pr", strconv.FormatInt(pr.Index, 10))
@@ -49,7 +77,20 @@ func Get(request GetRequest, gitea Gitea, git Git, outputDir string) (*GetRespon
 	metadata.Add("message", pr.Tip.RepoCommit.Message)
 	metadata.Add("author", pr.Tip.RepoCommit.Author.Name) // pr.Tip.Author is nil if committer not matched to a Gitea user
 	metadata.Add("author_email", pr.Tip.RepoCommit.Author.Email)
+	metadata.Add("author_login", pr.AuthorLogin())
 	metadata.Add("state", string(pr.State))
+	metadata.Add("milestone", pr.MilestoneTitle())
+	metadata.Add("assignees", strings.Join(pr.AssigneeLogins(), ","))
+	metadata.Add("is_fork", strconv.FormatBool(pr.IsFork()))
+	metadata.Add("head_repository", pr.HeadRepository())
+	metadata.Add("is_draft", strconv.FormatBool(pr.IsDraft(request.Source.WorkInProgressPrefixes())))
+	metadata.Add("trust_reason", trust)
+
+	if request.Version.Comment != "" {
+		if err := addCommentMetadata(ctx, &metadata, request, gitea, pr.Index); err != nil {
+			return nil, err
+		}
+	}
 
 	// Write version and metadata for reuse in PUT
 	path := filepath.Join(outputDir, ".git", "resource")
@@ -81,15 +122,15 @@ func Get(request GetRequest, gitea Gitea, git Git, outputDir string) (*GetRespon
 
 	switch tool := request.Params.IntegrationTool; tool {
 	case "rebase":
-		if err := git.Rebase(pr.Base.Ref, pr.Tip.SHA, request.Params.Submodules); err != nil {
-			return nil, err
+		if err := git.Rebase(ctx, pr.Base.Ref, pr.Tip.SHA, request.Params.Submodules); err != nil {
+			return nil, reportConflicts(ctx, err, git, path)
 		}
 	case "merge", "":
-		if err := git.Merge(pr.Tip.SHA, request.Params.Submodules); err != nil {
-			return nil, err
+		if err := git.Merge(ctx, pr.Tip.SHA, request.Params.Submodules); err != nil {
+			return nil, reportConflicts(ctx, err, git, path)
 		}
 	case "checkout":
-		if err := git.Checkout(pr.Head.Ref, pr.Tip.SHA, request.Params.Submodules); err != nil {
+		if err := git.Checkout(ctx, pr.Head.Ref, pr.Tip.SHA, request.Params.Submodules); err != nil {
 			return nil, err
 		}
 	default:
@@ -102,6 +143,66 @@ func Get(request GetRequest, gitea Gitea, git Git, outputDir string) (*GetRespon
 	}, nil
 }
 
+// addCommentMetadata adds the comment which triggered the version, along with the
+// groups captured by the comment trigger, to the metadata.
+func addCommentMetadata(ctx context.Context, metadata *Metadata, request GetRequest, manager Gitea, prIndex int64) error {
+	comments, err := manager.ListPullRequestComments(ctx, prIndex, time.Time{})
+	if err != nil {
+		return fmt.Errorf("failed to list comments: %s", err)
+	}
+
+	var comment *gitea.Comment
+	for _, c := range comments {
+		if strconv.FormatInt(c.ID, 10) == request.Version.Comment {
+			comment = c
+			break
+		}
+	}
+	if comment == nil {
+		return fmt.Errorf("comment with id '%s' does not exist", request.Version.Comment)
+	}
+
+	var author string
+	if comment.Poster != nil {
+		author = comment.Poster.UserName
+	}
+	metadata.Add("comment_id", request.Version.Comment)
+	metadata.Add("comment_author", author)
+	metadata.Add("comment_body", comment.Body)
+
+	if request.Source.CommentTrigger == "" {
+		return nil
+	}
+	trigger, err := regexp.Compile(request.Source.CommentTrigger)
+	if err != nil {
+		return fmt.Errorf("invalid comment trigger: %s", err)
+	}
+	match := trigger.FindStringSubmatch(comment.Body)
+	for i, name := range trigger.SubexpNames() {
+		if i == 0 || i >= len(match) {
+			continue
+		}
+		metadata.Add(fmt.Sprintf("comment_match_%d", i), match[i])
+		if name != "" {
+			metadata.Add("comment_match_"+name, match[i])
+		}
+	}
+	return nil
+}
+
+// reportConflicts adds the paths which conflict with the base to the error of a
+// failed merge or rebase, and writes them to the conflicts metadata file.
+func reportConflicts(ctx context.Context, integrationErr error, git Git, path string) error {
+	conflicts, err := git.ConflictingFiles(ctx)
+	if err != nil || len(conflicts) == 0 {
+		return integrationErr
+	}
+	if err := ioutil.WriteFile(filepath.Join(path, "conflicts"), []byte(strings.Join(conflicts, "\n")), 0644); err != nil {
+		return fmt.Errorf("failed to write metadata file conflicts: %s", err)
+	}
+	return fmt.Errorf("%s: conflicting files: %s", integrationErr, strings.Join(conflicts, ", "))
+}
+
 // GetParameters ...
 type GetParameters struct {
 	SkipDownload    bool   `json:"skip_download"`
@@ -1,11 +1,16 @@
 package resource_test
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,6 +18,7 @@ import (
 	resource "github.com/hur/gitea-pr-resource"
 	"github.com/hur/gitea-pr-resource/fakes"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGet(t *testing.T) {
@@ -23,6 +29,7 @@ func TestGet(t *testing.T) {
 		version        resource.Version
 		parameters     resource.GetParameters
 		pullRequest    *resource.PullRequest
+		comments       []*gitea.Comment
 		versionString  string
 		metadataString string
 		filesString    string
@@ -42,7 +49,49 @@ func TestGet(t *testing.T) {
 			parameters:     resource.GetParameters{},
 			pullRequest:    createTestPR(1, "master", false, false, nil, false, gitea.StateOpen),
 			versionString:  `{"pr":"pr1","commit":"commit1","committed":"0001-01-01T00:00:00Z","state":"open"}`,
-			metadataString: `[{"name":"pr","value":"1"},{"name":"title","value":"pr1 title"},{"name":"url","value":"pr1 url"},{"name":"head_name","value":"pr1"},{"name":"head_sha","value":"oid1"},{"name":"base_name","value":"master"},{"name":"base_sha","value":"sha"},{"name":"message","value":"commit message1"},{"name":"author","value":"login1"},{"name":"author_email","value":"user@example.com"},{"name":"state","value":"open"}]`,
+			metadataString: `[{"name":"pr","value":"1"},{"name":"title","value":"pr1 title"},{"name":"url","value":"pr1 url"},{"name":"head_name","value":"pr1"},{"name":"head_sha","value":"oid1"},{"name":"base_name","value":"master"},{"name":"base_sha","value":"sha"},{"name":"message","value":"commit message1"},{"name":"author","value":"login1"},{"name":"author_email","value":"user@example.com"},{"name":"author_login","value":"login1"},{"name":"state","value":"open"},{"name":"milestone","value":""},{"name":"assignees","value":""},{"name":"is_fork","value":"false"},{"name":"head_repository","value":"itsdalmo/test-repository"},{"name":"is_draft","value":"false"},{"name":"trust_reason","value":"unrestricted"}]`,
+		},
+		{
+			description: "get clones using ssh",
+			source: resource.Source{
+				Repository:    "itsdalmo/test-repository",
+				AccessToken:   "oauthtoken",
+				CloneProtocol: "ssh",
+				PrivateKey:    "private key",
+				KnownHosts:    "gitea.example.com ssh-ed25519 AAAA",
+			},
+			version: resource.Version{
+				PR:            "pr1",
+				Commit:        "commit1",
+				CommittedDate: time.Time{},
+				State:         gitea.StateOpen,
+			},
+			parameters:     resource.GetParameters{},
+			pullRequest:    createTestPR(1, "master", false, false, nil, false, gitea.StateOpen),
+			versionString:  `{"pr":"pr1","commit":"commit1","committed":"0001-01-01T00:00:00Z","state":"open"}`,
+			metadataString: `[{"name":"pr","value":"1"},{"name":"title","value":"pr1 title"},{"name":"url","value":"pr1 url"},{"name":"head_name","value":"pr1"},{"name":"head_sha","value":"oid1"},{"name":"base_name","value":"master"},{"name":"base_sha","value":"sha"},{"name":"message","value":"commit message1"},{"name":"author","value":"login1"},{"name":"author_email","value":"user@example.com"},{"name":"author_login","value":"login1"},{"name":"state","value":"open"},{"name":"milestone","value":""},{"name":"assignees","value":""},{"name":"is_fork","value":"false"},{"name":"head_repository","value":"itsdalmo/test-repository"},{"name":"is_draft","value":"false"},{"name":"trust_reason","value":"unrestricted"}]`,
+		},
+		{
+			description: "get works when the head repository was deleted",
+			source: resource.Source{
+				Repository:  "itsdalmo/test-repository",
+				AccessToken: "oauthtoken",
+				ForksOnly:   true,
+			},
+			version: resource.Version{
+				PR:            "pr1",
+				Commit:        "commit1",
+				CommittedDate: time.Time{},
+				State:         gitea.StateOpen,
+			},
+			parameters: resource.GetParameters{},
+			pullRequest: func() *resource.PullRequest {
+				pr := createTestPR(1, "master", false, true, nil, false, gitea.StateOpen)
+				pr.Head.Repository = nil
+				return pr
+			}(),
+			versionString:  `{"pr":"pr1","commit":"commit1","committed":"0001-01-01T00:00:00Z","state":"open"}`,
+			metadataString: `[{"name":"pr","value":"1"},{"name":"title","value":"pr1 title"},{"name":"url","value":"pr1 url"},{"name":"head_name","value":"pr1"},{"name":"head_sha","value":"oid1"},{"name":"base_name","value":"master"},{"name":"base_sha","value":"sha"},{"name":"message","value":"commit message1"},{"name":"author","value":"login1"},{"name":"author_email","value":"user@example.com"},{"name":"author_login","value":"login1"},{"name":"state","value":"open"},{"name":"milestone","value":""},{"name":"assignees","value":""},{"name":"is_fork","value":"true"},{"name":"head_repository","value":""},{"name":"is_draft","value":"false"},{"name":"trust_reason","value":"unrestricted"}]`,
 		},
 		{
 			description: "get supports rebasing",
@@ -61,7 +110,7 @@ func TestGet(t *testing.T) {
 			},
 			pullRequest:    createTestPR(1, "master", false, false, nil, false, gitea.StateOpen),
 			versionString:  `{"pr":"pr1","commit":"commit1","committed":"0001-01-01T00:00:00Z","state":"open"}`,
-			metadataString: `[{"name":"pr","value":"1"},{"name":"title","value":"pr1 title"},{"name":"url","value":"pr1 url"},{"name":"head_name","value":"pr1"},{"name":"head_sha","value":"oid1"},{"name":"base_name","value":"master"},{"name":"base_sha","value":"sha"},{"name":"message","value":"commit message1"},{"name":"author","value":"login1"},{"name":"author_email","value":"user@example.com"},{"name":"state","value":"open"}]`,
+			metadataString: `[{"name":"pr","value":"1"},{"name":"title","value":"pr1 title"},{"name":"url","value":"pr1 url"},{"name":"head_name","value":"pr1"},{"name":"head_sha","value":"oid1"},{"name":"base_name","value":"master"},{"name":"base_sha","value":"sha"},{"name":"message","value":"commit message1"},{"name":"author","value":"login1"},{"name":"author_email","value":"user@example.com"},{"name":"author_login","value":"login1"},{"name":"state","value":"open"},{"name":"milestone","value":""},{"name":"assignees","value":""},{"name":"is_fork","value":"false"},{"name":"head_repository","value":"itsdalmo/test-repository"},{"name":"is_draft","value":"false"},{"name":"trust_reason","value":"unrestricted"}]`,
 		},
 		{
 			description: "get supports checkout",
@@ -80,7 +129,7 @@ func TestGet(t *testing.T) {
 			},
 			pullRequest:    createTestPR(1, "master", false, false, nil, false, gitea.StateOpen),
 			versionString:  `{"pr":"pr1","commit":"commit1","committed":"0001-01-01T00:00:00Z","state":"open"}`,
-			metadataString: `[{"name":"pr","value":"1"},{"name":"title","value":"pr1 title"},{"name":"url","value":"pr1 url"},{"name":"head_name","value":"pr1"},{"name":"head_sha","value":"oid1"},{"name":"base_name","value":"master"},{"name":"base_sha","value":"sha"},{"name":"message","value":"commit message1"},{"name":"author","value":"login1"},{"name":"author_email","value":"user@example.com"},{"name":"state","value":"open"}]`,
+			metadataString: `[{"name":"pr","value":"1"},{"name":"title","value":"pr1 title"},{"name":"url","value":"pr1 url"},{"name":"head_name","value":"pr1"},{"name":"head_sha","value":"oid1"},{"name":"base_name","value":"master"},{"name":"base_sha","value":"sha"},{"name":"message","value":"commit message1"},{"name":"author","value":"login1"},{"name":"author_email","value":"user@example.com"},{"name":"author_login","value":"login1"},{"name":"state","value":"open"},{"name":"milestone","value":""},{"name":"assignees","value":""},{"name":"is_fork","value":"false"},{"name":"head_repository","value":"itsdalmo/test-repository"},{"name":"is_draft","value":"false"},{"name":"trust_reason","value":"unrestricted"}]`,
 		},
 		{
 			description: "get supports git_depth",
@@ -99,7 +148,68 @@ func TestGet(t *testing.T) {
 			},
 			pullRequest:    createTestPR(1, "master", false, false, nil, false, gitea.StateOpen),
 			versionString:  `{"pr":"pr1","commit":"commit1","committed":"0001-01-01T00:00:00Z","state":"open"}`,
-			metadataString: `[{"name":"pr","value":"1"},{"name":"title","value":"pr1 title"},{"name":"url","value":"pr1 url"},{"name":"head_name","value":"pr1"},{"name":"head_sha","value":"oid1"},{"name":"base_name","value":"master"},{"name":"base_sha","value":"sha"},{"name":"message","value":"commit message1"},{"name":"author","value":"login1"},{"name":"author_email","value":"user@example.com"},{"name":"state","value":"open"}]`,
+			metadataString: `[{"name":"pr","value":"1"},{"name":"title","value":"pr1 title"},{"name":"url","value":"pr1 url"},{"name":"head_name","value":"pr1"},{"name":"head_sha","value":"oid1"},{"name":"base_name","value":"master"},{"name":"base_sha","value":"sha"},{"name":"message","value":"commit message1"},{"name":"author","value":"login1"},{"name":"author_email","value":"user@example.com"},{"name":"author_login","value":"login1"},{"name":"state","value":"open"},{"name":"milestone","value":""},{"name":"assignees","value":""},{"name":"is_fork","value":"false"},{"name":"head_repository","value":"itsdalmo/test-repository"},{"name":"is_draft","value":"false"},{"name":"trust_reason","value":"unrestricted"}]`,
+		},
+		{
+			description: "get records why a PR was trusted",
+			source: resource.Source{
+				Repository:   "itsdalmo/test-repository",
+				AccessToken:  "oauthtoken",
+				TrustedTeams: []string{"itsdalmo/maintainers"},
+			},
+			version: resource.Version{
+				PR:            "pr1",
+				Commit:        "commit1",
+				CommittedDate: time.Time{},
+				State:         gitea.StateOpen,
+				TrustReason:   "trusted_team:itsdalmo/maintainers",
+			},
+			parameters:     resource.GetParameters{},
+			pullRequest:    createTestPR(1, "master", false, false, nil, false, gitea.StateOpen),
+			versionString:  `{"pr":"pr1","commit":"commit1","committed":"0001-01-01T00:00:00Z","state":"open","trust_reason":"trusted_team:itsdalmo/maintainers"}`,
+			metadataString: `[{"name":"pr","value":"1"},{"name":"title","value":"pr1 title"},{"name":"url","value":"pr1 url"},{"name":"head_name","value":"pr1"},{"name":"head_sha","value":"oid1"},{"name":"base_name","value":"master"},{"name":"base_sha","value":"sha"},{"name":"message","value":"commit message1"},{"name":"author","value":"login1"},{"name":"author_email","value":"user@example.com"},{"name":"author_login","value":"login1"},{"name":"state","value":"open"},{"name":"milestone","value":""},{"name":"assignees","value":""},{"name":"is_fork","value":"false"},{"name":"head_repository","value":"itsdalmo/test-repository"},{"name":"is_draft","value":"false"},{"name":"trust_reason","value":"trusted_team:itsdalmo/maintainers"}]`,
+		},
+		{
+			description: "get adds the trigger comment to the metadata",
+			source: resource.Source{
+				Repository:     "itsdalmo/test-repository",
+				AccessToken:    "oauthtoken",
+				CommentTrigger: `^/retest (?P<suite>\w+)`,
+			},
+			version: resource.Version{
+				PR:            "pr1",
+				Commit:        "commit1",
+				CommittedDate: time.Time{},
+				State:         gitea.StateOpen,
+				Comment:       "42",
+			},
+			parameters:  resource.GetParameters{},
+			pullRequest: createTestPR(1, "master", false, false, nil, false, gitea.StateOpen),
+			comments: []*gitea.Comment{
+				{ID: 41, Poster: &gitea.User{UserName: "maintainer"}, Body: "/retest unit"},
+				{ID: 42, Poster: &gitea.User{UserName: "maintainer"}, Body: "/retest e2e"},
+			},
+			versionString:  `{"pr":"pr1","commit":"commit1","committed":"0001-01-01T00:00:00Z","state":"open","comment":"42"}`,
+			metadataString: `[{"name":"pr","value":"1"},{"name":"title","value":"pr1 title"},{"name":"url","value":"pr1 url"},{"name":"head_name","value":"pr1"},{"name":"head_sha","value":"oid1"},{"name":"base_name","value":"master"},{"name":"base_sha","value":"sha"},{"name":"message","value":"commit message1"},{"name":"author","value":"login1"},{"name":"author_email","value":"user@example.com"},{"name":"author_login","value":"login1"},{"name":"state","value":"open"},{"name":"milestone","value":""},{"name":"assignees","value":""},{"name":"is_fork","value":"false"},{"name":"head_repository","value":"itsdalmo/test-repository"},{"name":"is_draft","value":"false"},{"name":"trust_reason","value":"unrestricted"},{"name":"comment_id","value":"42"},{"name":"comment_author","value":"maintainer"},{"name":"comment_body","value":"/retest e2e"},{"name":"comment_match_1","value":"e2e"},{"name":"comment_match_suite","value":"e2e"}]`,
+		},
+		{
+			description: "get pins the base to the commit in the version",
+			source: resource.Source{
+				Repository:          "itsdalmo/test-repository",
+				AccessToken:         "oauthtoken",
+				RebuildOnBaseChange: true,
+			},
+			version: resource.Version{
+				PR:            "pr1",
+				Commit:        "commit1",
+				CommittedDate: time.Time{},
+				State:         gitea.StateOpen,
+				BaseCommit:    "base1",
+			},
+			parameters:     resource.GetParameters{GitDepth: 1},
+			pullRequest:    createTestPR(1, "master", false, false, nil, false, gitea.StateOpen),
+			versionString:  `{"pr":"pr1","commit":"commit1","committed":"0001-01-01T00:00:00Z","state":"open","base_commit":"base1"}`,
+			metadataString: `[{"name":"pr","value":"1"},{"name":"title","value":"pr1 title"},{"name":"url","value":"pr1 url"},{"name":"head_name","value":"pr1"},{"name":"head_sha","value":"oid1"},{"name":"base_name","value":"master"},{"name":"base_sha","value":"sha"},{"name":"message","value":"commit message1"},{"name":"author","value":"login1"},{"name":"author_email","value":"user@example.com"},{"name":"author_login","value":"login1"},{"name":"state","value":"open"},{"name":"milestone","value":""},{"name":"assignees","value":""},{"name":"is_fork","value":"false"},{"name":"head_repository","value":"itsdalmo/test-repository"},{"name":"is_draft","value":"false"},{"name":"trust_reason","value":"unrestricted"}]`,
 		},
 	}
 
@@ -107,6 +217,7 @@ func TestGet(t *testing.T) {
 		t.Run(tc.description, func(t *testing.T) {
 			gitea := new(fakes.FakeGitea)
 			gitea.GetPullRequestReturns(tc.pullRequest, nil)
+			gitea.ListPullRequestCommentsReturns(tc.comments, nil)
 
 			git := new(fakes.FakeGit)
 			git.RevParseReturns("sha", nil)
@@ -115,7 +226,7 @@ func TestGet(t *testing.T) {
 			defer os.RemoveAll(dir)
 
 			input := resource.GetRequest{Source: tc.source, Version: tc.version, Params: tc.parameters}
-			output, err := resource.Get(input, gitea, git, dir)
+			output, err := resource.Get(context.Background(), input, gitea, git, dir)
 
 			// Validate output
 			if assert.NoError(t, err) {
@@ -139,6 +250,7 @@ func TestGet(t *testing.T) {
 					"message":      "commit message1",
 					"author":       "login1",
 					"author_email": "user@example.com",
+					"author_login": "login1",
 					"title":        "pr1 title",
 				}
 
@@ -150,35 +262,53 @@ func TestGet(t *testing.T) {
 			}
 
 			// Validate Github calls
+			assert.Equal(t, 0, gitea.ListTeamMembersCallCount())
 			if assert.Equal(t, 1, gitea.GetPullRequestCallCount()) {
-				pr, commit := gitea.GetPullRequestArgsForCall(0)
+				_, pr, commit := gitea.GetPullRequestArgsForCall(0)
 				assert.Equal(t, tc.version.PR, pr)
 				assert.Equal(t, tc.version.Commit, commit)
 			}
 
 			// Validate Git calls
 			if assert.Equal(t, 1, git.InitCallCount()) {
-				base := git.InitArgsForCall(0)
+				_, base := git.InitArgsForCall(0)
 				assert.Equal(t, tc.pullRequest.Base.Ref, base)
 			}
 
 			if assert.Equal(t, 1, git.PullCallCount()) {
-				url, base, depth, submodules, fetchTags := git.PullArgsForCall(0)
-				assert.Equal(t, tc.pullRequest.Head.Repository.CloneURL, url)
+				_, url, base, depth, submodules, fetchTags := git.PullArgsForCall(0)
+				assert.Equal(t, tc.source.CloneURL(tc.pullRequest.Base.Repository), url)
 				assert.Equal(t, tc.pullRequest.Base.Ref, base)
 				assert.Equal(t, tc.parameters.GitDepth, depth)
 				assert.Equal(t, tc.parameters.Submodules, submodules)
 				assert.Equal(t, tc.parameters.FetchTags, fetchTags)
 			}
 
+			if tc.version.BaseCommit == "" {
+				assert.Equal(t, 0, git.FetchCommitCallCount())
+			} else if assert.Equal(t, 1, git.FetchCommitCallCount()) {
+				_, url, sha, depth := git.FetchCommitArgsForCall(0)
+				assert.Equal(t, tc.source.CloneURL(tc.pullRequest.Base.Repository), url)
+				assert.Equal(t, tc.version.BaseCommit, sha)
+				assert.Equal(t, tc.parameters.GitDepth, depth)
+			}
+
+			if tc.version.BaseCommit == "" {
+				assert.Equal(t, 0, git.ResetCallCount())
+			} else if assert.Equal(t, 1, git.ResetCallCount()) {
+				_, sha, submodules := git.ResetArgsForCall(0)
+				assert.Equal(t, tc.version.BaseCommit, sha)
+				assert.Equal(t, tc.parameters.Submodules, submodules)
+			}
+
 			if assert.Equal(t, 1, git.RevParseCallCount()) {
-				base := git.RevParseArgsForCall(0)
+				_, base := git.RevParseArgsForCall(0)
 				assert.Equal(t, tc.pullRequest.Base.Ref, base)
 			}
 
 			if assert.Equal(t, 1, git.FetchCallCount()) {
-				url, pr, depth, submodules := git.FetchArgsForCall(0)
-				assert.Equal(t, tc.pullRequest.Head.Repository.CloneURL, url)
+				_, url, pr, depth, submodules := git.FetchArgsForCall(0)
+				assert.Equal(t, tc.source.CloneURL(tc.pullRequest.Base.Repository), url)
 				assert.Equal(t, tc.pullRequest.Index, int64(pr))
 				assert.Equal(t, tc.parameters.GitDepth, depth)
 				assert.Equal(t, tc.parameters.Submodules, submodules)
@@ -187,21 +317,21 @@ func TestGet(t *testing.T) {
 			switch tc.parameters.IntegrationTool {
 			case "rebase":
 				if assert.Equal(t, 1, git.RebaseCallCount()) {
-					branch, tip, submodules := git.RebaseArgsForCall(0)
+					_, branch, tip, submodules := git.RebaseArgsForCall(0)
 					assert.Equal(t, tc.pullRequest.Base.Ref, branch)
 					assert.Equal(t, tc.pullRequest.Tip.SHA, tip)
 					assert.Equal(t, tc.parameters.Submodules, submodules)
 				}
 			case "checkout":
 				if assert.Equal(t, 1, git.CheckoutCallCount()) {
-					branch, sha, submodules := git.CheckoutArgsForCall(0)
+					_, branch, sha, submodules := git.CheckoutArgsForCall(0)
 					assert.Equal(t, tc.pullRequest.Head.Ref, branch)
 					assert.Equal(t, tc.pullRequest.Tip.SHA, sha)
 					assert.Equal(t, tc.parameters.Submodules, submodules)
 				}
 			default:
 				if assert.Equal(t, 1, git.MergeCallCount()) {
-					tip, submodules := git.MergeArgsForCall(0)
+					_, tip, submodules := git.MergeArgsForCall(0)
 					assert.Equal(t, tc.pullRequest.Tip.SHA, tip)
 					assert.Equal(t, tc.parameters.Submodules, submodules)
 				}
@@ -210,6 +340,173 @@ func TestGet(t *testing.T) {
 	}
 }
 
+func TestGetReportsConflicts(t *testing.T) {
+	tests := []struct {
+		description string
+		parameters  resource.GetParameters
+		conflicts   []string
+		wantErr     string
+	}{
+		{
+			description: "get reports conflicting files when merging fails",
+			parameters:  resource.GetParameters{},
+			conflicts:   []string{"README.md", "main.go"},
+			wantErr:     "merge failed: exit status 1: conflicting files: README.md, main.go",
+		},
+		{
+			description: "get reports conflicting files when rebasing fails",
+			parameters:  resource.GetParameters{IntegrationTool: "rebase"},
+			conflicts:   []string{"README.md"},
+			wantErr:     "rebase failed: exit status 1: conflicting files: README.md",
+		},
+		{
+			description: "get returns the original error without conflicts",
+			parameters:  resource.GetParameters{},
+			wantErr:     "merge failed: exit status 1",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			fakeGitea := new(fakes.FakeGitea)
+			fakeGitea.GetPullRequestReturns(createTestPR(1, "master", false, false, nil, false, gitea.StateOpen), nil)
+
+			git := new(fakes.FakeGit)
+			git.RevParseReturns("sha", nil)
+			git.MergeReturns(errors.New("merge failed: exit status 1"))
+			git.RebaseReturns(errors.New("rebase failed: exit status 1"))
+			git.ConflictingFilesReturns(tc.conflicts, nil)
+
+			dir := createTestDirectory(t)
+			defer os.RemoveAll(dir)
+
+			input := resource.GetRequest{
+				Source:  resource.Source{Repository: "itsdalmo/test-repository", AccessToken: "oauthtoken"},
+				Version: resource.Version{PR: "pr1", Commit: "commit1", State: gitea.StateOpen},
+				Params:  tc.parameters,
+			}
+			_, err := resource.Get(context.Background(), input, fakeGitea, git, dir)
+			assert.EqualError(t, err, tc.wantErr)
+
+			if len(tc.conflicts) > 0 {
+				conflicts := readTestFile(t, filepath.Join(dir, ".git", "resource", "conflicts"))
+				assert.Equal(t, strings.Join(tc.conflicts, "\n"), conflicts)
+			}
+		})
+	}
+}
+
+func TestGetDoesNotLeakCredentials(t *testing.T) {
+	const token = "s3cr3t-token"
+	repository, sha := createTestRepository(t)
+
+	tests := []struct {
+		description string
+		cloneURL    string
+		verbose     bool
+		wantErr     string
+		wantOutput  string
+	}{
+		{
+			description: "no file in the output contains the token",
+			cloneURL:    repository,
+		},
+		{
+			description: "errors do not contain the token",
+			cloneURL:    "https://x-oauth-basic:" + token + "@127.0.0.1:1/owner/repo.git",
+			wantErr:     "pull failed: exit status 1",
+			wantOutput:  "fatal: ",
+		},
+		{
+			description: "verbose output does not contain the token",
+			cloneURL:    "https://x-oauth-basic:" + token + "@127.0.0.1:1/owner/repo.git",
+			verbose:     true,
+			wantErr:     "pull failed: exit status 1",
+			wantOutput:  "trace: ",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			pr := createTestPR(1, "master", false, false, nil, false, gitea.StateOpen)
+			pr.Base.Repository.CloneURL = tc.cloneURL
+			pr.Head.Repository.CloneURL = tc.cloneURL
+			pr.Tip.SHA = sha
+
+			fakeGitea := new(fakes.FakeGitea)
+			fakeGitea.GetPullRequestReturns(pr, nil)
+
+			dir := createTestDirectory(t)
+			defer os.RemoveAll(dir)
+
+			source := resource.Source{Repository: "itsdalmo/test-repository", AccessToken: token, Verbose: tc.verbose}
+			var output bytes.Buffer
+			git, err := resource.NewGitClient(&source, dir, &output)
+			require.NoError(t, err)
+			defer git.Close()
+
+			input := resource.GetRequest{
+				Source:  source,
+				Version: resource.Version{PR: "pr1", Commit: "commit1", State: gitea.StateOpen},
+			}
+			_, err = resource.Get(context.Background(), input, fakeGitea, git, dir)
+			assert.Contains(t, output.String(), tc.wantOutput)
+			assert.NotContains(t, output.String(), token)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.NotContains(t, err.Error(), token)
+				return
+			}
+			require.NoError(t, err)
+
+			err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+				if err != nil || info.IsDir() {
+					return err
+				}
+				b, err := os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				assert.NotContains(t, string(b), token, path)
+				return nil
+			})
+			require.NoError(t, err)
+		})
+	}
+}
+
+// createTestRepository creates a bare repository with a master branch and a pull
+// request (refs/pull/1/head) on top of it, and returns its URL and the head commit.
+func createTestRepository(t *testing.T) (string, string) {
+	git := func(dir string, args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+
+	bare := t.TempDir()
+	git(bare, "init", "-q", "--bare")
+
+	work := t.TempDir()
+	git(work, "init", "-q")
+	git(work, "checkout", "-q", "-b", "master")
+	require.NoError(t, os.WriteFile(filepath.Join(work, "README"), []byte("base"), 0644))
+	git(work, "add", "README")
+	git(work, "commit", "-q", "-m", "base")
+	git(work, "push", "-q", bare, "master")
+
+	require.NoError(t, os.WriteFile(filepath.Join(work, "README"), []byte("head"), 0644))
+	git(work, "commit", "-q", "-a", "-m", "head")
+	git(work, "push", "-q", bare, "HEAD:refs/pull/1/head")
+
+	return "file://" + bare, git(work, "rev-parse", "HEAD")
+}
+
 func TestGetSkipDownload(t *testing.T) {
 
 	tests := []struct {
@@ -242,7 +539,7 @@ func TestGetSkipDownload(t *testing.T) {
 
 			// Run the get and check output
 			input := resource.GetRequest{Source: tc.source, Version: tc.version, Params: tc.parameters}
-			output, err := resource.Get(input, github, git, dir)
+			output, err := resource.Get(context.Background(), input, github, git, dir)
 
 			if assert.NoError(t, err) {
 				assert.Equal(t, tc.version, output.Version)
@@ -276,6 +573,26 @@ func createTestPR(
 		labelObjects = append(labelObjects, &lObject)
 	}
 
+	headRepository := &gitea.Repository{
+		ID:       1,
+		FullName: "itsdalmo/test-repository",
+		CloneURL: fmt.Sprintf("pr%s url", n),
+		SSHURL:   fmt.Sprintf("pr%s ssh url", n),
+	}
+	if isCrossRepo {
+		headRepository = &gitea.Repository{
+			ID:       2,
+			FullName: "fork/test-repository",
+			CloneURL: fmt.Sprintf("pr%s url", n),
+			SSHURL:   fmt.Sprintf("pr%s ssh url", n),
+		}
+	}
+
+	title := fmt.Sprintf("pr%s title", n)
+	if isDraft {
+		title = "WIP: " + title
+	}
+
 	hasMerged := false
 	if state == gitea.StateClosed {
 		hasMerged = true
@@ -300,22 +617,27 @@ func createTestPR(
 		PullRequest: gitea.PullRequest{
 			URL:   fmt.Sprintf("pr%s url", n),
 			Index: int64(count),
-			Title: fmt.Sprintf("pr%s title", n),
+			Title: title,
+			Poster: &gitea.User{
+				UserName: fmt.Sprintf("login%s", n),
+			},
 			Base: &gitea.PRBranchInfo{
 				Name: baseName,
 				Ref:  baseName,
 				Repository: &gitea.Repository{
+					ID:       1,
+					FullName: "itsdalmo/test-repository",
 					CloneURL: fmt.Sprintf("pr%s url", n),
+					SSHURL:   fmt.Sprintf("pr%s ssh url", n),
 				},
 			},
 			Head: &gitea.PRBranchInfo{
-				Name: fmt.Sprintf("pr%s", n),
-				Ref:  fmt.Sprintf("pr%s", n),
-				Repository: &gitea.Repository{
-					CloneURL: fmt.Sprintf("pr%s url", n),
-				},
+				Name:       fmt.Sprintf("pr%s", n),
+				Ref:        fmt.Sprintf("pr%s", n),
+				Repository: headRepository,
 			},
 			Labels:    labelObjects,
+			Mergeable: true,
 			State:     state,
 			Closed:    ptr(time.Now()),
 			Merged:    ptr(time.Now()),
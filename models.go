@@ -1,9 +1,14 @@
 package resource
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"net/url"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"code.gitea.io/sdk/gitea"
@@ -11,20 +16,196 @@ import (
 
 // Source represents the configuration for the resource.
 type Source struct {
-	Repository    string          `json:"repository"`
-	Endpoint      string          `json:"endpoint"`
-	AccessToken   string          `json:"access_token"`
-	Paths         []string        `json:"paths"`
-	IgnorePaths   []string        `json:"ignore_paths"`
-	State         gitea.StateType `json:"state"`
-	DisableCISkip bool            `json:"disable_ci_skip"`
-	BaseBranch    string          `json:"base_branch"`
-	Labels        []string        `json:"labels"`
+	Repository      string            `json:"repository"`
+	Endpoint        string            `json:"endpoint"`
+	AccessToken     string            `json:"access_token"`
+	Paths           []string          `json:"paths"`
+	IgnorePaths     []string          `json:"ignore_paths"`
+	State           gitea.StateType   `json:"state"`
+	States          []gitea.StateType `json:"states"`
+	DisableCISkip   bool              `json:"disable_ci_skip"`
+	BaseBranch      string            `json:"base_branch"`
+	BaseBranches    []string          `json:"base_branches"`
+	HeadBranches    []string          `json:"head_branches"`
+	Authors         []string          `json:"authors"`
+	IgnoreAuthors   []string          `json:"ignore_authors"`
+	Milestones      []string          `json:"milestones"`
+	Assignees       []string          `json:"assignees"`
+	SkipUnmergeable bool              `json:"skip_unmergeable"`
+	Labels          []string          `json:"labels"`
+	IgnoreLabels    []string          `json:"ignore_labels"`
+	LabelsMatch     string            `json:"labels_match"`
+	DisableForks    bool              `json:"disable_forks"`
+	ForksOnly       bool              `json:"forks_only"`
+	IgnoreDrafts    bool              `json:"ignore_drafts"`
+	DraftPrefixes   []string          `json:"draft_prefixes"`
+
+	Username   string `json:"username"`
+	Password   string `json:"password"`
+	OAuthToken string `json:"oauth_token"`
+	Sudo       string `json:"sudo"`
+
+	RequiredReviewApprovals int  `json:"required_review_approvals"`
+	OfficialReviewsOnly     bool `json:"official_reviews_only"`
+
+	RequiredStatusContexts []string `json:"required_status_contexts"`
+
+	CommentTrigger string `json:"comment_trigger"`
+
+	RebuildOnBaseChange bool `json:"rebuild_on_base_change"`
+	EveryCommit         bool `json:"every_commit"`
+
+	MaxConcurrency   int  `json:"max_concurrency"`
+	SkipCommitLookup bool `json:"skip_commit_lookup"`
+
+	MaxRetries   *int   `json:"max_retries"`
+	RetryBackoff string `json:"retry_backoff"`
+
+	APITimeout string `json:"api_timeout"`
+	GitTimeout string `json:"git_timeout"`
+
+	CACert              string `json:"ca_cert"`
+	SkipSSLVerification bool   `json:"skip_ssl_verification"`
+	ClientCert          string `json:"client_cert"`
+	ClientKey           string `json:"client_key"`
+
+	PrivateKey    string `json:"private_key"`
+	KnownHosts    string `json:"known_hosts"`
+	CloneProtocol string `json:"clone_protocol"`
+
+	HTTPProxy    string            `json:"http_proxy"`
+	NoProxy      []string          `json:"no_proxy"`
+	ExtraHeaders map[string]string `json:"extra_headers"`
+	ProxyHeaders []string          `json:"proxy_headers"`
+
+	Verbose bool `json:"verbose"`
+
+	TrustedUsers  []string `json:"trusted_users"`
+	TrustedTeams  []string `json:"trusted_teams"`
+	OkToTestLabel string   `json:"ok_to_test_label"`
+}
+
+// Synthetic PR states which split up closed PRs by whether they were merged.
+const (
+	StateMerged         gitea.StateType = "merged"
+	StateClosedUnmerged gitea.StateType = "closed_unmerged"
+)
+
+// SelectedStates returns the PR states to trigger on. Defaults to open.
+func (s *Source) SelectedStates() []gitea.StateType {
+	if len(s.States) > 0 {
+		return s.States
+	}
+	if s.State != "" {
+		return []gitea.StateType{s.State}
+	}
+	return []gitea.StateType{gitea.StateOpen}
+}
+
+// DefaultOkToTestLabel is the label which allows PRs from untrusted authors to be built.
+const DefaultOkToTestLabel = "ok-to-test"
+
+// TrustLabel returns the label which allows PRs from untrusted authors to be built.
+func (s *Source) TrustLabel() string {
+	if s.OkToTestLabel == "" {
+		return DefaultOkToTestLabel
+	}
+	return s.OkToTestLabel
+}
+
+// DefaultDraftPrefixes mirrors the default WORK_IN_PROGRESS_PREFIXES of Gitea.
+var DefaultDraftPrefixes = []string{"WIP:", "[WIP]"}
+
+// WorkInProgressPrefixes returns the title prefixes that mark a PR as a draft.
+func (s *Source) WorkInProgressPrefixes() []string {
+	if len(s.DraftPrefixes) == 0 {
+		return DefaultDraftPrefixes
+	}
+	return s.DraftPrefixes
+}
+
+// GitCredentials returns the user name and password used by git. Gitea accepts access
+// and OAuth2 tokens as the password.
+func (s *Source) GitCredentials() (string, string) {
+	switch {
+	case s.Username != "":
+		return s.Username, s.Password
+	case s.OAuthToken != "":
+		return "oauth2", s.OAuthToken
+	default:
+		return "x-oauth-basic", s.AccessToken
+	}
+}
+
+// CloneURL returns the URL used to clone the repository, depending on the clone protocol.
+func (s *Source) CloneURL(repository *gitea.Repository) string {
+	if s.CloneProtocol == "ssh" {
+		return repository.SSHURL
+	}
+	return repository.CloneURL
+}
+
+// OriginHeaders returns the extra headers which are sent to Gitea, i.e. all except
+// those meant for the proxy.
+func (s *Source) OriginHeaders() map[string]string {
+	headers := make(map[string]string, len(s.ExtraHeaders))
+	for name, value := range s.ExtraHeaders {
+		headers[name] = value
+	}
+	for _, name := range s.ProxyHeaders {
+		delete(headers, name)
+	}
+	return headers
+}
+
+// Secrets returns the credentials in the source, which must not be disclosed.
+func (s *Source) Secrets() []string {
+	secrets := []string{s.AccessToken, s.Password, s.OAuthToken, s.ClientKey, s.PrivateKey}
+	if proxy, err := url.Parse(s.HTTPProxy); err == nil && proxy.User != nil {
+		password, _ := proxy.User.Password()
+		secrets = append(secrets, password)
+	}
+	return secrets
+}
+
+// RetryPolicy returns the number of times failed Gitea API requests are retried, and
+// the delay before the first retry.
+func (s *Source) RetryPolicy() (int, time.Duration) {
+	// Zero disables retries, so only an unset value falls back to the default.
+	retries := DefaultMaxRetries
+	if s.MaxRetries != nil {
+		retries = *s.MaxRetries
+	}
+	backoff, err := time.ParseDuration(s.RetryBackoff)
+	if err != nil || backoff <= 0 {
+		backoff = DefaultRetryBackoff
+	}
+	return retries, backoff
+}
+
+// Timeouts returns the timeout of each attempt of a Gitea API request and of each git
+// command. Zero means no timeout.
+func (s *Source) Timeouts() (time.Duration, time.Duration) {
+	api, _ := time.ParseDuration(s.APITimeout)
+	git, _ := time.ParseDuration(s.GitTimeout)
+	return api, git
 }
 
 func (s *Source) Validate() error {
-	if s.AccessToken == "" {
-		return errors.New("access_token must be set")
+	methods := 0
+	for _, set := range []bool{s.AccessToken != "", s.OAuthToken != "", s.Username != "" || s.Password != ""} {
+		if set {
+			methods++
+		}
+	}
+	if methods == 0 {
+		return errors.New("access_token, oauth_token or username and password must be set")
+	}
+	if methods > 1 {
+		return errors.New("access_token, oauth_token and username/password are mutually exclusive")
+	}
+	if (s.Username == "") != (s.Password == "") {
+		return errors.New("username and password must be set together")
 	}
 	if s.Repository == "" {
 		return errors.New("repository must be set")
@@ -33,7 +214,112 @@ func (s *Source) Validate() error {
 		return errors.New("endpoint must be set")
 	}
 
+	if s.DisableForks && s.ForksOnly {
+		return errors.New("disable_forks and forks_only are mutually exclusive")
+	}
+
+	if s.MaxConcurrency < 0 {
+		return errors.New("max_concurrency must not be negative")
+	}
+
+	if s.MaxRetries != nil && *s.MaxRetries < 0 {
+		return errors.New("max_retries must not be negative")
+	}
+
+	if s.RetryBackoff != "" {
+		if _, err := time.ParseDuration(s.RetryBackoff); err != nil {
+			return fmt.Errorf("invalid retry_backoff: %s", err)
+		}
+	}
+
+	if err := validateTimeout("api_timeout", s.APITimeout); err != nil {
+		return err
+	}
+
+	if err := validateTimeout("git_timeout", s.GitTimeout); err != nil {
+		return err
+	}
+
+	if (s.ClientCert == "") != (s.ClientKey == "") {
+		return errors.New("client_cert and client_key must be set together")
+	}
+
+	if _, err := s.TLSConfig(); err != nil {
+		return err
+	}
+
+	// Without known hosts, any host key would have to be accepted.
+	if s.PrivateKey != "" && s.KnownHosts == "" {
+		return errors.New("known_hosts must be set when using a private_key")
+	}
+
+	switch s.CloneProtocol {
+	case "", "https":
+	case "ssh":
+		if s.PrivateKey == "" {
+			return errors.New("private_key must be set to clone using ssh")
+		}
+	default:
+		return fmt.Errorf("clone_protocol value \"%s\" must be one of: https, ssh", s.CloneProtocol)
+	}
+
+	if s.HTTPProxy != "" {
+		if _, err := parseProxy(s.HTTPProxy); err != nil {
+			return err
+		}
+	}
+
+	if err := validateHeaders(s.ExtraHeaders); err != nil {
+		return err
+	}
+	// Proxy headers are only sent when tunneling API requests, since git has no way to
+	// send custom headers to a proxy. git authenticates with the credentials in http_proxy.
+	for _, name := range s.ProxyHeaders {
+		if _, ok := s.ExtraHeaders[name]; !ok {
+			return fmt.Errorf("proxy_headers: '%s' is not one of the extra_headers", name)
+		}
+	}
+
+	if s.RequiredReviewApprovals < 0 {
+		return errors.New("required_review_approvals must not be negative")
+	}
+
+	for _, team := range s.TrustedTeams {
+		if _, _, err := parseTeam(team); err != nil {
+			return fmt.Errorf("trusted_teams: %s", err)
+		}
+	}
+
+	for _, pattern := range append(append([]string{}, s.BaseBranches...), s.HeadBranches...) {
+		if _, err := MatchBranch([]string{pattern}, ""); err != nil {
+			return fmt.Errorf("invalid branch pattern \"%s\": %s", pattern, err)
+		}
+	}
+
+	for _, pattern := range append(append([]string{}, s.Authors...), s.IgnoreAuthors...) {
+		if _, err := MatchAuthor([]string{pattern}, ""); err != nil {
+			return fmt.Errorf("invalid author pattern \"%s\": %s", pattern, err)
+		}
+	}
+
+	if _, err := regexp.Compile(s.CommentTrigger); err != nil {
+		return fmt.Errorf("invalid comment_trigger: %s", err)
+	}
+
+	switch s.LabelsMatch {
+	case "", "any", "all":
+	default:
+		return fmt.Errorf("labels_match value \"%s\" must be one of: any, all", s.LabelsMatch)
+	}
+
+	for _, pattern := range append(append([]string{}, s.Labels...), s.IgnoreLabels...) {
+		if _, err := MatchLabel(pattern, ""); err != nil {
+			return fmt.Errorf("invalid label pattern \"%s\": %s", pattern, err)
+		}
+	}
+
 	switch s.State {
+	case "":
 	case gitea.StateOpen:
 	case gitea.StateClosed:
 	case gitea.StateAll:
@@ -41,6 +327,66 @@ func (s *Source) Validate() error {
 		return errors.New(fmt.Sprintf("state value \"%s\" must be one of: open, closed, all", s.State))
 	}
 
+	if s.State != "" && len(s.States) > 0 {
+		return errors.New("state and states are mutually exclusive")
+	}
+
+	for _, state := range s.States {
+		switch state {
+		case gitea.StateOpen:
+		case gitea.StateClosed:
+		case StateMerged:
+		case StateClosedUnmerged:
+		case gitea.StateAll:
+		default:
+			return errors.New(fmt.Sprintf("states value \"%s\" must be one of: open, closed, merged, closed_unmerged, all", state))
+		}
+	}
+
+	return nil
+}
+
+// TLSConfig returns the TLS configuration for connections to Gitea. Certificates signed
+// by the ca_cert are trusted in addition to the system certificates.
+func (s *Source) TLSConfig() (*tls.Config, error) {
+	config := &tls.Config{
+		InsecureSkipVerify: s.SkipSSLVerification,
+	}
+
+	if s.CACert != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(s.CACert)) {
+			return nil, errors.New("ca_cert does not contain a PEM encoded certificate")
+		}
+		config.RootCAs = pool
+	}
+
+	if s.ClientCert != "" {
+		cert, err := tls.X509KeyPair([]byte(s.ClientCert), []byte(s.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid client_cert or client_key: %s", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// validateTimeout checks that the option is empty or a non-negative duration.
+func validateTimeout(option, timeout string) error {
+	if timeout == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(timeout)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %s", option, err)
+	}
+	if d < 0 {
+		return fmt.Errorf("%s must not be negative", option)
+	}
 	return nil
 }
 
@@ -50,6 +396,9 @@ type Version struct {
 	Commit        string          `json:"commit"`
 	CommittedDate time.Time       `json:"committed,omitempty"`
 	State         gitea.StateType `json:"state"`
+	Comment       string          `json:"comment,omitempty"`
+	BaseCommit    string          `json:"base_commit,omitempty"`
+	TrustReason   string          `json:"trust_reason,omitempty"`
 }
 
 func NewVersion(pr *PullRequest) Version {
@@ -57,10 +406,28 @@ func NewVersion(pr *PullRequest) Version {
 		PR:            strconv.FormatInt(pr.Index, 10),
 		Commit:        pr.Head.Sha,
 		CommittedDate: pr.UpdatedDate().UTC(), // Unlike Github, Gitea doesn't normalize timestamps to UTC
-		State:         pr.State,
+		State:         pr.LifecycleState(),
 	}
 }
 
+// TimelineEvent is an event in the timeline of a pull request. The Gitea SDK does
+// not support the timeline, so only the fields used by the resource are decoded.
+type TimelineEvent struct {
+	Type     string       `json:"type"`
+	Created  time.Time    `json:"created_at"`
+	Body     string       `json:"body"`
+	OldTitle string       `json:"old_title"`
+	NewTitle string       `json:"new_title"`
+	Label    *gitea.Label `json:"label"`
+}
+
+// Timeline event types.
+const (
+	TimelineEventPush        = "pull_push"
+	TimelineEventChangeTitle = "change_title"
+	TimelineEventLabel       = "label"
+)
+
 // PullRequest represents a pull request and includes the tip (commit).
 type PullRequest struct {
 	gitea.PullRequest
@@ -79,10 +446,108 @@ func (pr *PullRequest) UpdatedDate() time.Time {
 		return *pr.Closed
 	}
 
-	//return *pr.Updated
+	// The commit date is set by the committer and may lie in the future, which would
+	// move the cursor past later pushes, so it is capped at the time Gitea last
+	// updated the PR.
+	if pr.Updated != nil && pr.Tip.Created.After(*pr.Updated) {
+		return *pr.Updated
+	}
 	return pr.Tip.Created
 }
 
+// LifecycleState returns the state of the PR, distinguishing merged PRs from
+// PRs that were closed without being merged.
+func (pr *PullRequest) LifecycleState() gitea.StateType {
+	if pr.State != gitea.StateClosed {
+		return pr.State
+	}
+	if pr.HasMerged {
+		return StateMerged
+	}
+	return StateClosedUnmerged
+}
+
+// MatchesState returns true if the PR is in one of the given states.
+func (pr *PullRequest) MatchesState(states []gitea.StateType) bool {
+	lifecycle := pr.LifecycleState()
+	for _, state := range states {
+		switch state {
+		case gitea.StateAll, lifecycle:
+			return true
+		case gitea.StateClosed:
+			if pr.State == gitea.StateClosed {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsFork returns true if the head of the PR lives in a different repository
+// than the base. PRs whose head repository has been deleted are treated as forks.
+func (pr *PullRequest) IsFork() bool {
+	if pr.Head == nil || pr.Head.Repository == nil {
+		return true
+	}
+	if pr.Base == nil || pr.Base.Repository == nil {
+		return false
+	}
+	return pr.Head.Repository.ID != pr.Base.Repository.ID
+}
+
+// IsDraft returns true if the PR title starts with one of the given work in
+// progress prefixes. Like Gitea, the comparison is case insensitive.
+func (pr *PullRequest) IsDraft(prefixes []string) bool {
+	return isDraftTitle(pr.Title, prefixes)
+}
+
+func isDraftTitle(title string, prefixes []string) bool {
+	title = strings.ToUpper(strings.TrimSpace(title))
+	for _, prefix := range prefixes {
+		if prefix != "" && strings.HasPrefix(title, strings.ToUpper(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// AuthorLogin returns the user name of the PR author.
+func (pr *PullRequest) AuthorLogin() string {
+	if pr.Poster == nil {
+		return ""
+	}
+	return pr.Poster.UserName
+}
+
+// MilestoneTitle returns the title of the milestone the PR is attached to.
+func (pr *PullRequest) MilestoneTitle() string {
+	if pr.Milestone == nil {
+		return ""
+	}
+	return pr.Milestone.Title
+}
+
+// AssigneeLogins returns the user names of the users assigned to the PR.
+func (pr *PullRequest) AssigneeLogins() []string {
+	var logins []string
+	for _, assignee := range pr.Assignees {
+		logins = append(logins, assignee.UserName)
+	}
+	if len(logins) == 0 && pr.Assignee != nil {
+		logins = append(logins, pr.Assignee.UserName)
+	}
+	return logins
+}
+
+// HeadRepository returns the full name of the repository the PR head lives in,
+// or an empty string if it has been deleted.
+func (pr *PullRequest) HeadRepository() string {
+	if pr.Head == nil || pr.Head.Repository == nil {
+		return ""
+	}
+	return pr.Head.Repository.FullName
+}
+
 // Metadata output from get/put steps.
 type Metadata []*MetadataField
 
@@ -1,6 +1,7 @@
 package resource_test
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"testing"
@@ -159,11 +160,11 @@ func TestPut(t *testing.T) {
 			// Run get so we have version and metadata for the put request
 			// (This is tested in in_test.go)
 			getInput := resource.GetRequest{Source: tc.source, Version: tc.version, Params: resource.GetParameters{}}
-			_, err := resource.Get(getInput, gitea, git, dir)
+			_, err := resource.Get(context.Background(), getInput, gitea, git, dir)
 			require.NoError(t, err)
 
 			putInput := resource.PutRequest{Source: tc.source, Params: tc.parameters}
-			output, err := resource.Put(putInput, gitea, dir)
+			output, err := resource.Put(context.Background(), putInput, gitea, dir)
 
 			// Validate output
 			if assert.NoError(t, err) {
@@ -173,7 +174,7 @@ func TestPut(t *testing.T) {
 			// Validate method calls put on Github.
 			if tc.parameters.Status != "" {
 				if assert.Equal(t, 1, gitea.UpdateCommitStatusCallCount()) {
-					commit, baseContext, context, status, targetURL, description := gitea.UpdateCommitStatusArgsForCall(0)
+					_, commit, baseContext, context, status, targetURL, description := gitea.UpdateCommitStatusArgsForCall(0)
 					assert.Equal(t, tc.version.Commit, commit)
 					assert.Equal(t, tc.parameters.BaseContext, baseContext)
 					assert.Equal(t, tc.parameters.Context, context)
@@ -185,7 +186,7 @@ func TestPut(t *testing.T) {
 
 			if tc.parameters.Comment != "" {
 				if assert.Equal(t, 1, gitea.PostCommentCallCount()) {
-					pr, comment := gitea.PostCommentArgsForCall(0)
+					_, pr, comment := gitea.PostCommentArgsForCall(0)
 					assert.Equal(t, tc.version.PR, pr)
 					assert.Equal(t, tc.parameters.Comment, comment)
 				}
@@ -281,7 +282,7 @@ func TestVariableSubstitution(t *testing.T) {
 
 			// Run get so we have version and metadata for the put request
 			getInput := resource.GetRequest{Source: tc.source, Version: tc.version, Params: resource.GetParameters{}}
-			_, err := resource.Get(getInput, gitea, git, dir)
+			_, err := resource.Get(context.Background(), getInput, gitea, git, dir)
 			require.NoError(t, err)
 
 			oldValue := os.Getenv(variableName)
@@ -290,18 +291,18 @@ func TestVariableSubstitution(t *testing.T) {
 			os.Setenv(variableName, variableValue)
 
 			putInput := resource.PutRequest{Source: tc.source, Params: tc.parameters}
-			_, err = resource.Put(putInput, gitea, dir)
+			_, err = resource.Put(context.Background(), putInput, gitea, dir)
 
 			if tc.parameters.TargetURL != "" {
 				if assert.Equal(t, 1, gitea.UpdateCommitStatusCallCount()) {
-					_, _, _, _, targetURL, _ := gitea.UpdateCommitStatusArgsForCall(0)
+					_, _, _, _, _, targetURL, _ := gitea.UpdateCommitStatusArgsForCall(0)
 					assert.Equal(t, tc.expectedTargetURL, targetURL)
 				}
 			}
 
 			if tc.parameters.Comment != "" {
 				if assert.Equal(t, 1, gitea.PostCommentCallCount()) {
-					_, comment := gitea.PostCommentArgsForCall(0)
+					_, _, comment := gitea.PostCommentArgsForCall(0)
 					assert.Equal(t, tc.expectedComment, comment)
 				}
 			}
@@ -0,0 +1,184 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	// DefaultMaxRetries is the default number of times a failed Gitea API request is retried.
+	DefaultMaxRetries = 3
+	// DefaultRetryBackoff is the default delay before the first retry, which doubles with every attempt.
+	DefaultRetryBackoff = time.Second
+	// MaxRetryDelay caps the delay between two attempts, including delays requested by the server.
+	MaxRetryDelay = time.Minute
+)
+
+// retryTransport retries requests which failed with a server error, were rate limited
+// or lost their connection, backing off exponentially between attempts. Unless the
+// timeout is zero, each attempt is aborted once it takes longer than the timeout.
+// Requests which are not idempotent, such as creating comments and statuses, are only
+// retried if they cannot have been processed by the server.
+type retryTransport struct {
+	base       http.RoundTripper
+	maxRetries int
+	backoff    time.Duration
+	timeout    time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Requests with a body can only be retried if the body can be recreated.
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for attempt := 1; ; attempt++ {
+		r := req
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(req.Context())
+			r.Body = body
+		}
+
+		resp, err := t.attempt(r)
+		timedOut := errors.Is(err, context.DeadlineExceeded) && req.Context().Err() == nil
+		if !(timedOut && idempotent(req.Method)) && !retryable(req.Method, resp, err) {
+			return resp, err
+		}
+
+		if attempt > t.maxRetries || !replayable {
+			if attempt == 1 {
+				return resp, err
+			}
+			if err != nil {
+				return nil, fmt.Errorf("giving up after %d attempts: %s", attempt, err)
+			}
+			resp.Body.Close()
+			return nil, fmt.Errorf("giving up after %d attempts: %s", attempt, resp.Status)
+		}
+
+		delay := t.delay(attempt, resp)
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt sends the request once, subject to the timeout. The timeout also applies to
+// reading the response body.
+func (t *retryTransport) attempt(req *http.Request) (*http.Response, error) {
+	if t.timeout == 0 {
+		return t.base.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose releases the context of a response once its body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// delay returns how long to wait before the next attempt. Delays requested by the
+// server through the Retry-After or X-RateLimit-Reset headers take precedence over
+// the exponential backoff.
+func (t *retryTransport) delay(attempt int, resp *http.Response) time.Duration {
+	delay := t.backoff
+	for i := 1; i < attempt && delay < MaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if resp != nil {
+		if requested, ok := requestedDelay(resp.Header, time.Now()); ok {
+			delay = requested
+		}
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	if delay > MaxRetryDelay {
+		delay = MaxRetryDelay
+	}
+	return delay
+}
+
+// retryable returns true if the request failed in a way which may succeed when retried.
+// Requests which are not idempotent are only retried if the connection was refused or
+// the request was rate limited, since they may have been processed otherwise.
+func retryable(method string, resp *http.Response, err error) bool {
+	if err != nil {
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			return true
+		}
+		return idempotent(method) && (errors.Is(err, syscall.ECONNRESET) ||
+			errors.Is(err, io.ErrUnexpectedEOF) ||
+			errors.Is(err, io.EOF))
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return true
+	case resp.StatusCode == http.StatusForbidden:
+		return resp.Header.Get("X-RateLimit-Remaining") == "0"
+	case resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented:
+		return idempotent(method)
+	}
+	return false
+}
+
+// idempotent returns true if sending a request with the method twice has the same
+// effect as sending it once.
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// requestedDelay parses the Retry-After header (in seconds or as an HTTP date) and the
+// X-RateLimit-Reset header (as a unix timestamp) when the rate limit has been exhausted.
+func requestedDelay(header http.Header, now time.Time) (time.Duration, bool) {
+	if retryAfter := header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if date, err := http.ParseTime(retryAfter); err == nil {
+			return date.Sub(now), true
+		}
+	}
+	if header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return time.Unix(reset, 0).Sub(now), true
+		}
+	}
+	return 0, false
+}
@@ -1,2 +0,0 @@
-#!/bin/sh
-exec echo "$X_OAUTH_BASIC_TOKEN"
\ No newline at end of file
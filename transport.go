@@ -0,0 +1,186 @@
+package resource
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// newHTTPClient creates the HTTP client used for Gitea API requests, applying the
+// TLS, proxy, header and retry settings of the source. The client also authenticates
+// requests using the OAuth2 token, if any.
+func newHTTPClient(s *Source) (*http.Client, error) {
+	tlsConfig, err := s.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	proxy, err := ProxyFunc(s, http.ProxyFromEnvironment)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	transport.Proxy = proxy
+	if len(s.ProxyHeaders) > 0 {
+		// Only the headers meant for the proxy are sent when tunneling HTTPS requests,
+		// since the others may e.g. authenticate with Gitea.
+		transport.ProxyConnectHeader = make(http.Header)
+		for _, name := range s.ProxyHeaders {
+			transport.ProxyConnectHeader.Set(name, s.ExtraHeaders[name])
+		}
+	}
+
+	headers := s.OriginHeaders()
+	if s.OAuthToken != "" {
+		headers["Authorization"] = "Bearer " + s.OAuthToken
+	}
+
+	retries, backoff := s.RetryPolicy()
+	apiTimeout, _ := s.Timeouts()
+	return &http.Client{
+		Transport: &redactTransport{
+			base: &retryTransport{
+				base: &headerTransport{
+					base:    transport,
+					headers: headers,
+				},
+				maxRetries: retries,
+				backoff:    backoff,
+				timeout:    apiTimeout,
+			},
+			redactor:  NewRedactor(s.Secrets()...),
+			sensitive: sensitiveHeaders(s),
+		},
+	}, nil
+}
+
+// sensitiveHeaders returns the names of the request headers which may contain credentials.
+func sensitiveHeaders(s *Source) []string {
+	names := []string{"Authorization", "Proxy-Authorization"}
+	for name := range s.ExtraHeaders {
+		names = append(names, name)
+	}
+	return names
+}
+
+// redactTransport masks credentials in the requests attached to responses, which the
+// SDK includes in its error messages, and in errors.
+type redactTransport struct {
+	base      http.RoundTripper
+	redactor  *Redactor
+	sensitive []string
+}
+
+func (t *redactTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, t.redactor.Error(err)
+	}
+	if resp.Request != nil {
+		r := resp.Request.Clone(resp.Request.Context())
+		for _, name := range t.sensitive {
+			if r.Header.Get(name) != "" {
+				r.Header.Set(name, Redacted)
+			}
+		}
+		for _, values := range r.Header {
+			for i := range values {
+				values[i] = t.redactor.Redact(values[i])
+			}
+		}
+		if r.URL.User != nil {
+			r.URL.User = url.User(Redacted)
+		}
+		resp.Request = r
+	}
+	return resp, nil
+}
+
+// headerTransport adds extra headers to every request.
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(t.headers) == 0 {
+		return t.base.RoundTrip(req)
+	}
+	r := req.Clone(req.Context())
+	for name, value := range t.headers {
+		r.Header.Set(name, value)
+	}
+	return t.base.RoundTrip(r)
+}
+
+// ProxyFunc returns the http_proxy of the source, or the environment proxy if it is not
+// set, for all hosts not matching no_proxy. Like git, no_proxy also applies to the
+// proxy configured through the environment.
+func ProxyFunc(s *Source, environment func(*http.Request) (*url.URL, error)) (func(*http.Request) (*url.URL, error), error) {
+	var proxy *url.URL
+	if s.HTTPProxy != "" {
+		var err error
+		if proxy, err = parseProxy(s.HTTPProxy); err != nil {
+			return nil, err
+		}
+	}
+	return func(req *http.Request) (*url.URL, error) {
+		if MatchNoProxy(s.NoProxy, req.URL.Hostname()) {
+			return nil, nil
+		}
+		if proxy == nil {
+			return environment(req)
+		}
+		return proxy, nil
+	}, nil
+}
+
+func parseProxy(s string) (*url.URL, error) {
+	proxy, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid http_proxy: %s", err)
+	}
+	if proxy.Scheme == "" || proxy.Host == "" {
+		return nil, fmt.Errorf("invalid http_proxy '%s': expected a URL such as http://proxy:3128", s)
+	}
+	return proxy, nil
+}
+
+// MatchNoProxy returns true if the host should not be proxied according to the no_proxy
+// entries. Like curl, an entry matches the host itself and its subdomains (a leading
+// dot is ignored), and "*" matches all hosts. Ports are ignored.
+func MatchNoProxy(entries []string, host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, entry := range entries {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if h, _, err := net.SplitHostPort(entry); err == nil {
+			entry = h
+		}
+		entry = strings.TrimPrefix(entry, ".")
+		switch {
+		case entry == "":
+		case entry == "*":
+			return true
+		case host == entry, strings.HasSuffix(host, "."+entry):
+			return true
+		}
+	}
+	return false
+}
+
+// validateHeaders checks that the headers cannot be used to inject other headers or
+// git configuration.
+func validateHeaders(headers map[string]string) error {
+	for name, value := range headers {
+		if name == "" || strings.ContainsAny(name, ": \t\r\n") {
+			return fmt.Errorf("invalid extra_headers name '%s'", name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("invalid extra_headers value for '%s'", name)
+		}
+	}
+	return nil
+}